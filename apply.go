@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/weaveworks/footloose/pkg/cluster"
+)
+
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Reconcile the cluster machines with the cluster configuration",
+	Long: `Reconcile the cluster machines with the cluster configuration.
+
+Machines missing are created, machines which spec has changed are recreated and
+machines which aren't part of the configuration any more are deleted.`,
+	RunE: apply,
+}
+
+var applyOptions struct {
+	config    string
+	dryRun    bool
+	parallel  int
+	skipCheck bool
+}
+
+func init() {
+	applyCmd.Flags().StringVarP(&applyOptions.config, "config", "c", Footloose, "Cluster configuration file")
+	applyCmd.Flags().BoolVar(&applyOptions.dryRun, "dry-run", false, "Print the actions to take without performing them")
+	applyCmd.Flags().IntVarP(&applyOptions.parallel, "parallel", "p", 1, "Number of machines to operate on concurrently")
+	applyCmd.Flags().BoolVar(&applyOptions.skipCheck, "skip-image-check", false, "Don't check images not provided by footloose can run machines")
+	footloose.AddCommand(applyCmd)
+}
+
+func apply(cmd *cobra.Command, args []string) error {
+	cluster, err := cluster.NewFromFile(configFile(applyOptions.config))
+	if err != nil {
+		return err
+	}
+	cluster.SetParallel(applyOptions.parallel).SetSkipImageCheck(applyOptions.skipCheck)
+
+	plan, err := cluster.Plan()
+	if err != nil {
+		return err
+	}
+	if len(plan) == 0 {
+		fmt.Println("No changes")
+		return nil
+	}
+	for _, action := range plan {
+		fmt.Println(action)
+	}
+	if applyOptions.dryRun {
+		return nil
+	}
+	return cluster.Apply(plan)
+}
@@ -5,24 +5,35 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/weaveworks/footloose/pkg/cluster"
+	"github.com/weaveworks/footloose/pkg/config"
 )
 
 var configCreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a cluster configuration",
-	RunE:  configCreate,
+	Long: `Create a cluster configuration.
+
+The configuration starts from the default single machine configuration or,
+with --template, from a template listed by 'footloose config templates'. Flags
+given explicitly override the template values, for all its machine groups.`,
+	RunE: configCreate,
 }
 
 var configCreateOptions struct {
-	override bool
-	file     string
+	override     bool
+	file         string
+	template     string
+	templateDirs []string
 }
 
 func init() {
 	configCreateCmd.Flags().StringVarP(&configCreateOptions.file, "config", "c", Footloose, "Cluster configuration file")
 	configCreateCmd.Flags().BoolVar(&configCreateOptions.override, "override", false, "Override configuration file if it exists")
+	configCreateCmd.Flags().StringVarP(&configCreateOptions.template, "template", "t", "", "Template to create the configuration from")
+	configCreateCmd.Flags().StringArrayVar(&configCreateOptions.templateDirs, "template-dir", nil, "Directory holding user templates, NAME.yaml files, on top of "+defaultTemplateDir+" (can be repeated)")
 
 	name := &defaultConfig.Cluster.Name
 	configCreateCmd.PersistentFlags().StringVarP(name, "name", "n", *name, "Name of the cluster")
@@ -30,6 +41,9 @@ func init() {
 	private := &defaultConfig.Cluster.PrivateKey
 	configCreateCmd.PersistentFlags().StringVarP(private, "key", "k", *private, "Name of the private and public key files")
 
+	keyType := &defaultConfig.Cluster.KeyType
+	configCreateCmd.PersistentFlags().StringVar(keyType, "key-type", *keyType, "Type of the SSH key to generate: {rsa,ecdsa,ed25519}")
+
 	networks := &defaultConfig.Machines[0].Spec.Networks
 	configCreateCmd.PersistentFlags().StringSliceVar(networks, "networks", *networks, "Networks names the machines are assigned to")
 
@@ -58,9 +72,51 @@ func configExists(path string) bool {
 	return !info.IsDir()
 }
 
+// applyConfigFlags overrides conf with the configuration flags given
+// explicitly, which values are in defaultConfig.
+func applyConfigFlags(flags *pflag.FlagSet, conf *config.Config) {
+	if flags.Changed("name") {
+		conf.Cluster.Name = defaultConfig.Cluster.Name
+	}
+	if flags.Changed("key") {
+		conf.Cluster.PrivateKey = defaultConfig.Cluster.PrivateKey
+	}
+	if flags.Changed("key-type") {
+		conf.Cluster.KeyType = defaultConfig.Cluster.KeyType
+	}
+	defaults := &defaultConfig.Machines[0]
+	for i := range conf.Machines {
+		machines := &conf.Machines[i]
+		if flags.Changed("networks") {
+			machines.Spec.Networks = defaults.Spec.Networks
+		}
+		if flags.Changed("replicas") {
+			machines.Count = defaults.Count
+		}
+		if flags.Changed("image") {
+			machines.Spec.Image = defaults.Spec.Image
+		}
+		if flags.Changed("privileged") {
+			machines.Spec.Privileged = defaults.Spec.Privileged
+		}
+		if flags.Changed("cmd") {
+			machines.Spec.Cmd = defaults.Spec.Cmd
+		}
+	}
+}
+
 func configCreate(cmd *cobra.Command, args []string) error {
 	opts := &configCreateOptions
-	cluster, err := cluster.New(defaultConfig)
+	conf := defaultConfig
+	if opts.template != "" {
+		template, err := loadTemplate(opts.template, templateDirs(opts.templateDirs))
+		if err != nil {
+			return err
+		}
+		conf = *template
+		applyConfigFlags(cmd.Flags(), &conf)
+	}
+	cluster, err := cluster.New(conf)
 	if err != nil {
 		return err
 	}
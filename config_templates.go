@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var configTemplatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "List the templates 'footloose config create' can start from",
+	Long: `List the templates 'footloose config create --template' can start from.
+
+On top of the templates built in footloose, user templates are read from
+` + defaultTemplateDir + ` and the directories given with --template-dir. A
+user template is a configuration file named after the template, NAME.yaml. It
+takes precedence over the built-in template with the same name.`,
+	Args: cobra.NoArgs,
+	RunE: configTemplates,
+}
+
+var configTemplatesOptions struct {
+	templateDirs []string
+}
+
+func init() {
+	configTemplatesCmd.Flags().StringArrayVar(&configTemplatesOptions.templateDirs, "template-dir", nil, "Directory holding user templates, NAME.yaml files, on top of "+defaultTemplateDir+" (can be repeated)")
+	configCmd.AddCommand(configTemplatesCmd)
+}
+
+func configTemplates(cmd *cobra.Command, args []string) error {
+	templates, err := listTemplates(templateDirs(configTemplatesOptions.templateDirs))
+	if err != nil {
+		return err
+	}
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(table, "NAME\tDESCRIPTION")
+	for _, t := range templates {
+		fmt.Fprintf(table, "%s\t%s\n", t.name, t.description)
+	}
+	return table.Flush()
+}
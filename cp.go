@@ -0,0 +1,42 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/weaveworks/footloose/pkg/cluster"
+)
+
+var cpCmd = &cobra.Command{
+	Use:   "cp SOURCE DESTINATION...",
+	Short: "Copy files to and from machines",
+	Long: `Copy files and directories between the host and machines.
+
+Paths inside machines are of the form HOSTNAME:PATH. Copying from the host to
+multiple machines is done by giving several destinations. Exp:
+
+  footloose cp ./config node0:/etc/app/ node1:/etc/app/
+  footloose cp -r node0:/var/log/app ./logs`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: cp,
+}
+
+var cpOptions struct {
+	config string
+	opts   cluster.CopyOptions
+}
+
+func init() {
+	cpCmd.Flags().StringVarP(&cpOptions.config, "config", "c", Footloose, "Cluster configuration file")
+	cpCmd.Flags().BoolVarP(&cpOptions.opts.Recursive, "recursive", "r", false, "Copy directories recursively")
+	cpCmd.Flags().StringVar(&cpOptions.opts.Owner, "owner", "", "Owner (user[:group]) of the copied files")
+	cpCmd.Flags().StringVar(&cpOptions.opts.Mode, "mode", "", "Mode of the copied files, as understood by chmod")
+	footloose.AddCommand(cpCmd)
+}
+
+func cp(cmd *cobra.Command, args []string) error {
+	cluster, err := cluster.NewFromFile(configFile(cpOptions.config))
+	if err != nil {
+		return err
+	}
+	return cluster.Copy(args[0], args[1:], cpOptions.opts)
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 
 	"github.com/weaveworks/footloose/pkg/cluster"
@@ -13,18 +15,46 @@ var createCmd = &cobra.Command{
 }
 
 var createOptions struct {
-	config string
+	config      string
+	wait        bool
+	waitTimeout time.Duration
+	parallel    int
+	snapshot    string
+	keep        bool
+	skipCheck   bool
 }
 
 func init() {
 	createCmd.Flags().StringVarP(&createOptions.config, "config", "c", Footloose, "Cluster configuration file")
+	createCmd.Flags().IntVarP(&createOptions.parallel, "parallel", "p", 1, "Number of machines to operate on concurrently")
+	createCmd.Flags().BoolVar(&createOptions.wait, "wait", false, "Wait for the machines to be ready")
+	createCmd.Flags().BoolVar(&createOptions.keep, "keep-on-failure", false, "Keep the machines created when the cluster creation fails")
+	createCmd.Flags().BoolVar(&createOptions.skipCheck, "skip-image-check", false, "Don't check images not provided by footloose can run machines")
+	createCmd.Flags().StringVar(&createOptions.snapshot, "from-snapshot", "", "Create the machines from the images of a snapshot")
+	createCmd.Flags().DurationVar(&createOptions.waitTimeout, "wait-timeout", 5*time.Minute, "Maximum time to wait for machines to be ready")
 	footloose.AddCommand(createCmd)
 }
 
 func create(cmd *cobra.Command, args []string) error {
-	cluster, err := cluster.NewFromFile(configFile(createOptions.config))
+	c, err := cluster.NewFromFile(configFile(createOptions.config))
 	if err != nil {
 		return err
 	}
-	return cluster.Create()
+	c.SetParallel(createOptions.parallel).SetKeepOnFailure(createOptions.keep).SetSkipImageCheck(createOptions.skipCheck)
+	if createOptions.snapshot != "" {
+		snapshot, err := cluster.LoadSnapshot(c.Name(), createOptions.snapshot)
+		if err != nil {
+			return err
+		}
+		if err := c.SetSnapshot(snapshot); err != nil {
+			return err
+		}
+	}
+	if err := c.Create(); err != nil {
+		return err
+	}
+	if createOptions.wait {
+		return c.Wait(nil, createOptions.waitTimeout)
+	}
+	return nil
 }
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"fmt"
+
 	"github.com/spf13/cobra"
 
 	"github.com/weaveworks/footloose/pkg/cluster"
@@ -9,22 +11,48 @@ import (
 var deleteCmd = &cobra.Command{
 	Use:   "delete",
 	Short: "Delete a cluster",
-	RunE:  delete,
+	Long: `Delete a cluster.
+
+The cluster is described by its configuration file unless --name or
+--all-orphans is given. Those find the cluster machines by label and don't
+need a configuration file.`,
+	RunE: delete,
 }
 
 var deleteOptions struct {
-	config string
+	config     string
+	parallel   int
+	name       string
+	allOrphans bool
 }
 
 func init() {
 	deleteCmd.Flags().StringVarP(&deleteOptions.config, "config", "c", Footloose, "Cluster configuration file")
+	deleteCmd.Flags().IntVarP(&deleteOptions.parallel, "parallel", "p", 1, "Number of machines to operate on concurrently")
+	deleteCmd.Flags().StringVar(&deleteOptions.name, "name", "", "Delete the cluster with this name, without reading its configuration file")
+	deleteCmd.Flags().BoolVar(&deleteOptions.allOrphans, "all-orphans", false, "Delete the clusters which configuration file doesn't exist or doesn't describe them any more")
 	footloose.AddCommand(deleteCmd)
 }
 
 func delete(cmd *cobra.Command, args []string) error {
+	if deleteOptions.name != "" && deleteOptions.allOrphans {
+		return fmt.Errorf("--name and --all-orphans are mutually exclusive")
+	}
+	if deleteOptions.name != "" {
+		return cluster.DeleteByName(deleteOptions.name, deleteOptions.parallel)
+	}
+	if deleteOptions.allOrphans {
+		deleted, err := cluster.DeleteOrphans(deleteOptions.parallel)
+		for _, name := range deleted {
+			fmt.Println(name)
+		}
+		return err
+	}
+
 	cluster, err := cluster.NewFromFile(configFile(deleteOptions.config))
 	if err != nil {
 		return err
 	}
+	cluster.SetParallel(deleteOptions.parallel)
 	return cluster.Delete()
 }
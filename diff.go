@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/weaveworks/footloose/pkg/cluster"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [HOSTNAME...]",
+	Short: "Show how machines differ from the cluster configuration",
+	Long: `Show, for each machine, how the running machine differs from the cluster
+configuration. Machines are compared with the spec they were created from.`,
+	RunE: diff,
+}
+
+var diffOptions struct {
+	config string
+	output string
+}
+
+func init() {
+	diffCmd.Flags().StringVarP(&diffOptions.config, "config", "c", Footloose, "Cluster configuration file")
+	diffCmd.Flags().StringVarP(&diffOptions.output, "output", "o", "text", "Output formatting options: {json,text}.")
+	footloose.AddCommand(diffCmd)
+}
+
+func diff(cmd *cobra.Command, args []string) error {
+	c, err := cluster.NewFromFile(configFile(diffOptions.config))
+	if err != nil {
+		return err
+	}
+	if diffOptions.output != "text" && diffOptions.output != "json" {
+		return fmt.Errorf("unknown formatter '%s'", diffOptions.output)
+	}
+	diffs, err := c.Diff(args)
+	if err != nil {
+		return err
+	}
+
+	if diffOptions.output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(diffs)
+	}
+	for _, d := range diffs {
+		fmt.Printf("%s: %s\n", d.Machine, d.State)
+		for _, change := range d.Changes {
+			fmt.Printf("  %s\n", change)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/weaveworks/footloose/pkg/cluster"
+)
+
+var eventsCmd = &cobra.Command{
+	Use:   "events",
+	Short: "Stream the state transitions of the cluster machines",
+	Long: `Stream the state transitions of the cluster machines as they happen: created,
+started, stopped, died, oom and deleted. Stop streaming with Ctrl-C.
+
+Ignite VMs are polled for changes and don't report died and oom events.`,
+	Args: cobra.NoArgs,
+	RunE: events,
+}
+
+var eventsOptions struct {
+	config string
+	name   string
+	output string
+}
+
+func init() {
+	eventsCmd.Flags().StringVarP(&eventsOptions.config, "config", "c", Footloose, "Cluster configuration file")
+	eventsCmd.Flags().StringVarP(&eventsOptions.name, "name", "n", "", "Use the recorded state of the cluster with this name instead of the configuration file")
+	eventsCmd.Flags().StringVarP(&eventsOptions.output, "output", "o", "table", "Output formatting options: {json,table}.")
+	footloose.AddCommand(eventsCmd)
+}
+
+func events(cmd *cobra.Command, args []string) error {
+	var formatter cluster.EventFormatter
+	switch eventsOptions.output {
+	case "json":
+		formatter = new(cluster.JSONEventFormatter)
+	case "table":
+		formatter = new(cluster.TableEventFormatter)
+	default:
+		return fmt.Errorf("unknown formatter '%s'", eventsOptions.output)
+	}
+	c, err := newCluster(eventsOptions.config, eventsOptions.name)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel()
+	}()
+
+	stream := make(chan cluster.Event)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- c.Events(ctx, stream)
+	}()
+	for {
+		select {
+		case event := <-stream:
+			if err := formatter.FormatEvent(os.Stdout, &event); err != nil {
+				return err
+			}
+		case err := <-errc:
+			return err
+		}
+	}
+}
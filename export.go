@@ -0,0 +1,37 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/weaveworks/footloose/pkg/cluster"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export ARCHIVE",
+	Short: "Export the cluster to an archive",
+	Long: `Snapshot the cluster machines and write an archive with the cluster
+configuration, its SSH key and the machine images. The cluster can be recreated
+from the archive on another host with 'footloose import ARCHIVE'.`,
+	Args: cobra.ExactArgs(1),
+	RunE: export,
+}
+
+var exportOptions struct {
+	config   string
+	parallel int
+}
+
+func init() {
+	exportCmd.Flags().StringVarP(&exportOptions.config, "config", "c", Footloose, "Cluster configuration file")
+	exportCmd.Flags().IntVarP(&exportOptions.parallel, "parallel", "p", 1, "Number of machines to operate on concurrently")
+	footloose.AddCommand(exportCmd)
+}
+
+func export(cmd *cobra.Command, args []string) error {
+	cluster, err := cluster.NewFromFile(configFile(exportOptions.config))
+	if err != nil {
+		return err
+	}
+	cluster.SetParallel(exportOptions.parallel)
+	return cluster.Export(args[0])
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var exportLogsCmd = &cobra.Command{
+	Use:   "export-logs DIR",
+	Short: "Gather diagnostics of the cluster machines in a directory",
+	Long: `Gather diagnostics of the cluster machines in a directory, eg. to upload them
+as a CI artifact.
+
+Each machine gets a directory named after its hostname with its status, docker
+(or ignite) inspect output, container output and, when it's running, its
+journal, dmesg, systemctl status and network configuration. The cluster
+configuration is copied to DIR/footloose.yaml. Stopped and partially created
+machines are exported with what can be gathered from them.`,
+	Args: cobra.ExactArgs(1),
+	RunE: exportLogs,
+}
+
+var exportLogsOptions struct {
+	config   string
+	name     string
+	parallel int
+}
+
+func init() {
+	exportLogsCmd.Flags().StringVarP(&exportLogsOptions.config, "config", "c", Footloose, "Cluster configuration file")
+	exportLogsCmd.Flags().StringVarP(&exportLogsOptions.name, "name", "n", "", "Use the recorded state of the cluster with this name instead of the configuration file")
+	exportLogsCmd.Flags().IntVarP(&exportLogsOptions.parallel, "parallel", "p", 1, "Number of machines to operate on concurrently")
+	footloose.AddCommand(exportLogsCmd)
+}
+
+func exportLogs(cmd *cobra.Command, args []string) error {
+	c, err := newCluster(exportLogsOptions.config, exportLogsOptions.name)
+	if err != nil {
+		return err
+	}
+	return c.SetParallel(exportLogsOptions.parallel).ExportLogs(args[0])
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/weaveworks/footloose/pkg/cluster"
+)
+
+var extendCmd = &cobra.Command{
+	Use:   "extend DURATION",
+	Short: "Push back the deadline of a cluster with a TTL",
+	Long: `Push back the deadline at which 'footloose reap' deletes a cluster, eg.
+'footloose extend 2h'. An expired cluster is extended from now.`,
+	Args: cobra.ExactArgs(1),
+	RunE: extend,
+}
+
+var extendOptions struct {
+	config string
+	name   string
+}
+
+func init() {
+	extendCmd.Flags().StringVarP(&extendOptions.config, "config", "c", Footloose, "Cluster configuration file")
+	extendCmd.Flags().StringVarP(&extendOptions.name, "name", "n", "", "Extend the cluster with this name, without reading its configuration file")
+	footloose.AddCommand(extendCmd)
+}
+
+func extend(cmd *cobra.Command, args []string) error {
+	d, err := time.ParseDuration(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid duration '%s'", args[0])
+	}
+	name := extendOptions.name
+	if name == "" {
+		c, err := cluster.NewFromFile(configFile(extendOptions.config))
+		if err != nil {
+			return err
+		}
+		name = c.Name()
+	}
+	deadline, err := cluster.Extend(name, d)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Cluster %s now expires at %s\n", name, deadline.Local().Format(time.RFC3339))
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"os"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+
+	"github.com/weaveworks/footloose/pkg/cluster"
 )
 
 // Footloose is the default name of the footloose file.
@@ -24,6 +26,15 @@ func configFile(f string) string {
 	return f
 }
 
+// newCluster creates a Cluster from its configuration file or, when name is
+// given, from the state footloose recorded for the cluster name.
+func newCluster(config, name string) (*cluster.Cluster, error) {
+	if name != "" {
+		return cluster.NewFromState(name)
+	}
+	return cluster.NewFromFile(configFile(config))
+}
+
 func main() {
 	if err := footloose.Execute(); err != nil {
 		log.Fatal(err)
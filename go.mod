@@ -3,8 +3,8 @@ module github.com/weaveworks/footloose
 require (
 	github.com/blang/semver v3.5.1+incompatible
 	github.com/docker/docker v1.13.1
-	github.com/docker/go-connections v0.4.0 // indirect
-	github.com/docker/go-units v0.3.3 // indirect
+	github.com/docker/go-connections v0.4.0
+	github.com/docker/go-units v0.3.3
 	github.com/ghodss/yaml v1.0.0
 	github.com/google/go-github/v24 v24.0.1
 	github.com/gorilla/mux v1.7.3
@@ -13,9 +13,9 @@ require (
 	github.com/pkg/errors v0.8.1
 	github.com/sirupsen/logrus v1.3.0
 	github.com/spf13/cobra v0.0.3
-	github.com/spf13/pflag v1.0.3 // indirect
+	github.com/spf13/pflag v1.0.3
 	github.com/stretchr/testify v1.2.2
-	golang.org/x/crypto v0.0.0-20190308221718-c2843e01d9a2 // indirect
+	golang.org/x/crypto v0.0.0-20190308221718-c2843e01d9a2
 	gopkg.in/yaml.v2 v2.2.2
 )
 
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var imageCmd = &cobra.Command{
+	Use:   "image",
+	Short: "Manage machine images",
+}
+
+func init() {
+	footloose.AddCommand(imageCmd)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/weaveworks/footloose/pkg/image"
+)
+
+var imageBuildCmd = &cobra.Command{
+	Use:   "build [DISTRO...]",
+	Short: "Build machine images",
+	Long: `Build the machine images of the given distros, or of all distros listed by
+'footloose image list', from the Dockerfiles built in footloose.
+
+Images are tagged with the version of footloose. --base builds the image of a
+single distro from another base image, derived from the distro image, adding
+the systemd and sshd layers footloose needs on top of it. --tag is then
+required.`,
+	RunE: imageBuild,
+}
+
+var imageBuildOptions struct {
+	base string
+	tag  string
+}
+
+func init() {
+	imageBuildCmd.Flags().StringVar(&imageBuildOptions.base, "base", "", "Base image to build from instead of the distro image")
+	imageBuildCmd.Flags().StringVarP(&imageBuildOptions.tag, "tag", "t", "", "Name and tag of the built image (default "+image.Repository+"/DISTRO:VERSION)")
+	imageCmd.AddCommand(imageBuildCmd)
+}
+
+func imageBuild(cmd *cobra.Command, args []string) error {
+	opts := &imageBuildOptions
+	distros := args
+	if len(distros) == 0 {
+		distros = image.Distros()
+	}
+	if (opts.base != "" || opts.tag != "") && len(distros) != 1 {
+		return fmt.Errorf("--base and --tag need a single distro")
+	}
+	if opts.base != "" && opts.tag == "" {
+		return fmt.Errorf("--base needs --tag")
+	}
+
+	for _, distro := range distros {
+		name := opts.tag
+		if name == "" {
+			name = image.Name(distro, imageTag(version))
+		}
+		if err := image.Build(distro, opts.base, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/weaveworks/footloose/pkg/docker"
+	"github.com/weaveworks/footloose/pkg/image"
+)
+
+var imageCheckCmd = &cobra.Command{
+	Use:   "check IMAGE",
+	Short: "Check an image can run footloose machines",
+	Long: `Check an image can run footloose machines.
+
+The image is pulled if it isn't present locally, then a throwaway container of
+it is started to look for what footloose machines need: bash, sshd and its
+host keys, an init system and a /run directory footloose can mount a tmpfs on.
+Hints are given to fix the image.
+
+'footloose create' runs this check for images not provided by footloose, once
+per image ID.`,
+	Args: cobra.ExactArgs(1),
+	RunE: imageCheck,
+}
+
+var imageCheckOptions struct {
+	cmd    string
+	output string
+}
+
+func init() {
+	imageCheckCmd.Flags().StringVar(&imageCheckOptions.cmd, "cmd", "", "Command the machines run, when not the image init system")
+	imageCheckCmd.Flags().StringVarP(&imageCheckOptions.output, "output", "o", "table", "Output formatting options: {json,table}.")
+	imageCmd.AddCommand(imageCheckCmd)
+}
+
+func imageCheck(cmd *cobra.Command, args []string) error {
+	if imageCheckOptions.output != "table" && imageCheckOptions.output != "json" {
+		return fmt.Errorf("unknown formatter '%s'", imageCheckOptions.output)
+	}
+	if _, err := docker.PullIfNotPresent(args[0], 2); err != nil {
+		return err
+	}
+	report, err := image.Check(args[0], imageCheckOptions.cmd)
+	if err != nil {
+		return err
+	}
+	if imageCheckOptions.output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	} else {
+		err = report.Format(os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+	if !report.OK() {
+		return fmt.Errorf("%s can't run footloose machines", args[0])
+	}
+	return nil
+}
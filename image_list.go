@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/weaveworks/footloose/pkg/docker"
+	"github.com/weaveworks/footloose/pkg/image"
+)
+
+var imageListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the distros footloose can build machine images for",
+	Args:  cobra.NoArgs,
+	RunE:  imageList,
+}
+
+func init() {
+	imageCmd.AddCommand(imageListCmd)
+}
+
+func imageList(cmd *cobra.Command, args []string) error {
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(table, "DISTRO\tIMAGE\tPRESENT")
+	for _, distro := range image.Distros() {
+		name := image.Name(distro, imageTag(version))
+		present := "no"
+		if docker.ImageExists(name) {
+			present = "yes"
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\n", distro, name, present)
+	}
+	return table.Flush()
+}
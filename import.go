@@ -0,0 +1,37 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/weaveworks/footloose/pkg/cluster"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import ARCHIVE",
+	Short: "Create a cluster from an archive",
+	Long: `Load the machine images of an archive written by 'footloose export', write
+the cluster configuration and SSH key and create the cluster from the images.
+No image registry access is needed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: importCluster,
+}
+
+var importOptions struct {
+	dir      string
+	parallel int
+}
+
+func init() {
+	importCmd.Flags().StringVarP(&importOptions.dir, "dir", "d", ".", "Directory to write the cluster configuration and SSH key to")
+	importCmd.Flags().IntVarP(&importOptions.parallel, "parallel", "p", 1, "Number of machines to operate on concurrently")
+	footloose.AddCommand(importCmd)
+}
+
+func importCluster(cmd *cobra.Command, args []string) error {
+	cluster, err := cluster.Import(args[0], importOptions.dir)
+	if err != nil {
+		return err
+	}
+	cluster.SetParallel(importOptions.parallel)
+	return cluster.Create()
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/weaveworks/footloose/pkg/docker"
+	"github.com/weaveworks/footloose/pkg/image"
+)
+
+var loadImageCmd = &cobra.Command{
+	Use:   "load-image ARCHIVE...",
+	Short: "Load machine images from archives",
+	Long: `Load the images of archives written by 'docker save' so machines can be
+created without registry access, eg. with 'pullPolicy: Never'. Archives are
+checked to contain tagged images before being loaded.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: loadImage,
+}
+
+func init() {
+	footloose.AddCommand(loadImageCmd)
+}
+
+func loadImage(cmd *cobra.Command, args []string) error {
+	if err := docker.IsRunning(); err != nil {
+		return err
+	}
+	// Check all the archives before loading any.
+	for _, archive := range args {
+		if _, err := image.ArchiveTags(archive); err != nil {
+			return err
+		}
+	}
+	for _, archive := range args {
+		log.Infof("Loading %s ...", archive)
+		tags, err := image.Load(archive)
+		if err != nil {
+			return err
+		}
+		for _, tag := range tags {
+			log.Infof("Loaded image %s", tag)
+		}
+	}
+	return nil
+}
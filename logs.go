@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/weaveworks/footloose/pkg/cluster"
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs [HOSTNAME...]",
+	Short: "Show the logs of cluster machines",
+	Long: `Show the logs of cluster machines, or of all machines when no hostname is given.
+
+Logs are read from the systemd journal inside the machines, from the container
+output (the console of ignite VMs) or both. Lines are prefixed with the machine
+hostname and streams of different machines are interleaved.`,
+	RunE: logs,
+}
+
+var logsOptions struct {
+	config string
+	name   string
+	source string
+	follow bool
+	units  []string
+	since  string
+}
+
+func init() {
+	logsCmd.Flags().StringVarP(&logsOptions.config, "config", "c", Footloose, "Cluster configuration file")
+	logsCmd.Flags().StringVarP(&logsOptions.name, "name", "n", "", "Use the recorded state of the cluster with this name instead of the configuration file")
+	logsCmd.Flags().StringVar(&logsOptions.source, "source", cluster.LogsJournal, "Where to read logs from: {journal,container,all}")
+	logsCmd.Flags().BoolVarP(&logsOptions.follow, "follow", "f", false, "Keep streaming new logs")
+	logsCmd.Flags().StringArrayVarP(&logsOptions.units, "unit", "u", nil, "Only show the journal of this systemd unit (can be repeated)")
+	logsCmd.Flags().StringVar(&logsOptions.since, "since", "", "Only show logs newer than a duration (eg. 10m) or a timestamp")
+	footloose.AddCommand(logsCmd)
+}
+
+func logs(cmd *cobra.Command, args []string) error {
+	c, err := newCluster(logsOptions.config, logsOptions.name)
+	if err != nil {
+		return err
+	}
+	return c.Logs(args, cluster.LogsOptions{
+		Source: logsOptions.source,
+		Follow: logsOptions.follow,
+		Units:  logsOptions.units,
+		Since:  logsOptions.since,
+	}, os.Stdout)
+}
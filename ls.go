@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/weaveworks/footloose/pkg/cluster"
+)
+
+var lsCmd = &cobra.Command{
+	Use:   "ls",
+	Short: "List the clusters on the host",
+	Long: `List the clusters created by footloose on the host, whether their
+configuration file is around or not. Clusters which configuration file doesn't
+exist or doesn't describe them any more are marked as orphans.`,
+	Args: cobra.NoArgs,
+	RunE: ls,
+}
+
+var lsOptions struct {
+	output string
+}
+
+func init() {
+	lsCmd.Flags().StringVarP(&lsOptions.output, "output", "o", "table", "Output formatting options: {json,table}.")
+	footloose.AddCommand(lsCmd)
+}
+
+func ls(cmd *cobra.Command, args []string) error {
+	if lsOptions.output != "table" && lsOptions.output != "json" {
+		return fmt.Errorf("unknown formatter '%s'", lsOptions.output)
+	}
+	clusters, err := cluster.ListClusters()
+	if err != nil {
+		return err
+	}
+
+	if lsOptions.output == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(clusters)
+	}
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(table, "NAME\tMACHINES\tRUNNING\tSTOPPED\tCONFIG")
+	for _, c := range clusters {
+		config := c.Config
+		if config == "" {
+			config = "-"
+		}
+		if c.Orphan {
+			config += " (orphan)"
+		}
+		fmt.Fprintf(table, "%s\t%d\t%d\t%d\t%s\n", c.Name, c.Machines, c.Running, c.Stopped, config)
+	}
+	return table.Flush()
+}
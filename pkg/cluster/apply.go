@@ -0,0 +1,220 @@
+package cluster
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/weaveworks/footloose/pkg/config"
+	"github.com/weaveworks/footloose/pkg/docker"
+)
+
+// Action types.
+const (
+	// ActionCreate creates a machine missing from the cluster.
+	ActionCreate = "create"
+	// ActionDelete deletes a machine that isn't part of the cluster
+	// configuration any more.
+	ActionDelete = "delete"
+	// ActionRecreate deletes and creates again a machine which spec changed.
+	ActionRecreate = "recreate"
+)
+
+// Action is an operation needed to reconcile the cluster machines with the
+// cluster configuration.
+type Action struct {
+	// Type is the action type. One of "create", "delete" or "recreate".
+	Type string `json:"type"`
+	// Machine is the name of the machine the action is about.
+	Machine string `json:"machine"`
+	// Reasons lists why a machine needs to be recreated.
+	Reasons []string `json:"reasons,omitempty"`
+
+	job machineJob
+}
+
+func (a *Action) String() string {
+	s := a.Type + " " + a.Machine
+	if len(a.Reasons) > 0 {
+		s += " (" + strings.Join(a.Reasons, ", ") + ")"
+	}
+	return s
+}
+
+// changed appends a description of the change to changes if got and want
+// differ.
+func changed(changes []string, field string, got, want interface{}) []string {
+	if reflect.DeepEqual(got, want) {
+		return changes
+	}
+	return append(changes, f("%s: %v -> %v", field, got, want))
+}
+
+func sortedStrings(s []string) []string {
+	sorted := append([]string{}, s...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+// inspectedChanges returns the differences between spec and what can be
+// inspected of the container created from it.
+func inspectedChanges(inspect *types.ContainerJSON, spec *config.Machine) []string {
+	var changes []string
+
+	changes = changed(changes, "image", inspect.Config.Image, spec.Image)
+	changes = changed(changes, "cmd", machineCommandFromContainer(inspect.Config.Cmd), machineCommand(spec))
+	changes = changed(changes, "privileged", inspect.HostConfig.Privileged, spec.Privileged)
+
+	var networks []string
+	if inspect.NetworkSettings != nil {
+		for name := range inspect.NetworkSettings.Networks {
+			networks = append(networks, name)
+		}
+	}
+	wantNetworks := spec.Networks
+	if len(wantNetworks) == 0 {
+		wantNetworks = []string{"bridge"}
+	}
+	changes = changed(changes, "networks", sortedStrings(networks), sortedStrings(wantNetworks))
+
+	var ports, wantPorts []string
+	for port := range inspect.HostConfig.PortBindings {
+		ports = append(ports, string(port))
+	}
+	for _, mapping := range spec.PortMappings {
+		protocol := mapping.Protocol
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		wantPorts = append(wantPorts, f("%d/%s", mapping.ContainerPort, protocol))
+	}
+	changes = changed(changes, "ports", sortedStrings(ports), sortedStrings(wantPorts))
+
+	var volumes, wantVolumes []string
+	for _, mount := range inspect.Mounts {
+		// footloose always mounts the cgroup hierarchy.
+		if mount.Destination == "/sys/fs/cgroup" {
+			continue
+		}
+		volumes = append(volumes, mount.Destination)
+	}
+	for _, volume := range spec.Volumes {
+		wantVolumes = append(wantVolumes, volume.Destination)
+	}
+	changes = changed(changes, "volumes", sortedStrings(volumes), sortedStrings(wantVolumes))
+
+	return changes
+}
+
+// inspectedMachineChanges returns the differences between the spec of m and
+// what can be inspected of the container running it.
+func inspectedMachineChanges(m *Machine) ([]string, error) {
+	var inspect types.ContainerJSON
+	if err := docker.InspectObject(m.name, ".", &inspect); err != nil {
+		return nil, err
+	}
+	return inspectedChanges(&inspect, m.spec), nil
+}
+
+// clusterContainers returns the names of the containers labelled as part of
+// the cluster.
+func (c *Cluster) clusterContainers() ([]string, error) {
+	return docker.List("{{.Names}}", "label="+clusterLabel+"="+c.spec.Cluster.Name)
+}
+
+// Plan computes the actions needed to reconcile the cluster machines with the
+// cluster configuration: machines missing are created, machines which spec
+// changed are recreated and containers labelled as part of the cluster but not
+// described in its configuration any more are deleted.
+//
+// Changes and surplus machines are only detected for the docker backend.
+func (c *Cluster) Plan() ([]*Action, error) {
+	if err := docker.IsRunning(); err != nil {
+		return nil, err
+	}
+
+	var plan []*Action
+	desired := make(map[string]bool)
+	for _, job := range c.machineJobs() {
+		m := job.machine
+		desired[m.name] = true
+
+		if !m.IsCreated() {
+			plan = append(plan, &Action{Type: ActionCreate, Machine: m.name, job: job})
+			continue
+		}
+		if m.IsIgnite() {
+			continue
+		}
+		changes, err := machineChanges(m)
+		if err != nil {
+			return nil, err
+		}
+		if len(changes) > 0 {
+			plan = append(plan, &Action{Type: ActionRecreate, Machine: m.name, Reasons: changes, job: job})
+		}
+	}
+
+	containers, err := c.clusterContainers()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range containers {
+		if desired[name] {
+			continue
+		}
+		m := &Machine{
+			spec:     &config.Machine{},
+			name:     name,
+			hostname: name,
+		}
+		plan = append(plan, &Action{Type: ActionDelete, Machine: name, job: machineJob{machine: m}})
+	}
+
+	return plan, nil
+}
+
+// Apply performs the actions of a plan computed by Plan.
+func (c *Cluster) Apply(plan []*Action) error {
+	actions := make(map[*Machine]*Action)
+	var jobs []machineJob
+	needCreate := false
+	for _, action := range plan {
+		actions[action.job.machine] = action
+		jobs = append(jobs, action.job)
+		if action.Type != ActionDelete {
+			needCreate = true
+		}
+	}
+
+	// Machines are created like Create does, cluster host hooks included.
+	if needCreate {
+		if err := c.prepareCreate(); err != nil {
+			return err
+		}
+		if err := c.runClusterHostHooks(hookHostPreCreate); err != nil {
+			return err
+		}
+	}
+
+	err := c.runJobs(jobs, func(m *Machine, i int) error {
+		switch actions[m].Type {
+		case ActionCreate:
+			return c.CreateMachine(m, i)
+		case ActionDelete:
+			return c.DeleteMachine(m, i)
+		case ActionRecreate:
+			if err := c.DeleteMachine(m, i); err != nil {
+				return err
+			}
+			return c.CreateMachine(m, i)
+		}
+		return nil
+	})
+	if err != nil || !needCreate {
+		return err
+	}
+	return c.runClusterHostHooks(hookHostPostCreate)
+}
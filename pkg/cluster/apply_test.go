@@ -0,0 +1,74 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weaveworks/footloose/pkg/config"
+)
+
+func inspectedMachine() *types.ContainerJSON {
+	return &types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			HostConfig: &container.HostConfig{
+				PortBindings: nat.PortMap{"22/tcp": nil},
+			},
+		},
+		Mounts: []types.MountPoint{
+			{Destination: "/sys/fs/cgroup"},
+		},
+		Config: &container.Config{
+			Image: "quay.io/footloose/centos7",
+			Cmd:   []string{"/sbin/init"},
+		},
+		NetworkSettings: &types.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{"bridge": {}},
+		},
+	}
+}
+
+func TestInspectedChanges(t *testing.T) {
+	spec := config.Machine{
+		Image:        "quay.io/footloose/centos7",
+		PortMappings: []config.PortMapping{{ContainerPort: 22}},
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(spec *config.Machine)
+		changes []string
+	}{
+		{"unchanged", func(*config.Machine) {}, nil},
+		{"image", func(spec *config.Machine) {
+			spec.Image = "quay.io/footloose/ubuntu18.04"
+		}, []string{"image: quay.io/footloose/centos7 -> quay.io/footloose/ubuntu18.04"}},
+		{"cmd", func(spec *config.Machine) {
+			spec.Cmd = "/lib/systemd/systemd"
+		}, []string{"cmd: /sbin/init -> /lib/systemd/systemd"}},
+		{"privileged", func(spec *config.Machine) {
+			spec.Privileged = true
+		}, []string{"privileged: false -> true"}},
+		{"networks", func(spec *config.Machine) {
+			spec.Networks = []string{"footloose-net"}
+		}, []string{"networks: [bridge] -> [footloose-net]"}},
+		{"ports", func(spec *config.Machine) {
+			spec.PortMappings = append(spec.PortMappings, config.PortMapping{ContainerPort: 53, Protocol: "udp"})
+		}, []string{"ports: [22/tcp] -> [22/tcp 53/udp]"}},
+		{"volumes", func(spec *config.Machine) {
+			spec.Volumes = []config.Volume{{Destination: "/data"}}
+		}, []string{"volumes: [] -> [/data]"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s := spec
+			test.modify(&s)
+			assert.Equal(t, test.changes, inspectedChanges(inspectedMachine(), &s))
+		})
+	}
+}
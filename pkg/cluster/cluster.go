@@ -7,8 +7,8 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
-	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/docker/docker/api/types"
@@ -20,6 +20,22 @@ import (
 	"github.com/weaveworks/footloose/pkg/docker"
 	"github.com/weaveworks/footloose/pkg/exec"
 	"github.com/weaveworks/footloose/pkg/ignite"
+	"github.com/weaveworks/footloose/pkg/image"
+)
+
+// Labels set on footloose containers.
+const (
+	// ownerLabel marks containers created by footloose.
+	ownerLabel = "works.weave.owner"
+	// clusterLabel is the name of the cluster a container is part of.
+	clusterLabel = "works.weave.cluster"
+	// configLabel is the absolute path of the configuration file of the
+	// cluster a container is part of.
+	configLabel = "works.weave.config"
+	// createdLabel is the time a container was created, in RFC 3339 format.
+	createdLabel = "works.weave.created"
+	// ttlLabel is the TTL of the cluster a container is part of.
+	ttlLabel = "works.weave.ttl"
 )
 
 // Container represents a running machine.
@@ -31,6 +47,18 @@ type Container struct {
 type Cluster struct {
 	spec     config.Config
 	keyStore *KeyStore
+	// parallel is the maximum number of machines operated on concurrently.
+	parallel int
+	// snapshot, when set, holds the images to create machines from.
+	snapshot *Snapshot
+	// keepOnFailure keeps the machines created by a failed Create.
+	keepOnFailure bool
+	// skipImageCheck disables checking images not provided by footloose
+	// before creating machines.
+	skipImageCheck bool
+	// configPath is the absolute path of the configuration file, when the
+	// cluster was created from one.
+	configPath string
 }
 
 // New creates a new cluster. It takes as input the description of the cluster
@@ -61,7 +89,12 @@ func NewFromFile(path string) (*Cluster, error) {
 	if err != nil {
 		return nil, err
 	}
-	return NewFromYAML(data)
+	c, err := NewFromYAML(data)
+	if err != nil {
+		return nil, err
+	}
+	c.configPath, _ = filepath.Abs(path)
+	return c, nil
 }
 
 // SetKeyStore provides a store where to persist public keys for this Cluster.
@@ -70,6 +103,27 @@ func (c *Cluster) SetKeyStore(keyStore *KeyStore) *Cluster {
 	return c
 }
 
+// SetParallel sets the maximum number of machines lifecycle operations
+// (create, start, stop, delete, ...) act on concurrently. Defaults to 1.
+func (c *Cluster) SetParallel(n int) *Cluster {
+	c.parallel = n
+	return c
+}
+
+// SetKeepOnFailure makes Create keep the machines it created when it fails,
+// instead of deleting them. Useful to debug failures.
+func (c *Cluster) SetKeepOnFailure(keep bool) *Cluster {
+	c.keepOnFailure = keep
+	return c
+}
+
+// SetSkipImageCheck disables checking images not provided by footloose can
+// run machines before creating them.
+func (c *Cluster) SetSkipImageCheck(skip bool) *Cluster {
+	c.skipImageCheck = skip
+	return c
+}
+
 // Name returns the cluster name.
 func (c *Cluster) Name() string {
 	return c.spec.Cluster.Name
@@ -114,20 +168,69 @@ func (c *Cluster) machine(spec *config.Machine, i int) *Machine {
 	}
 }
 
-func (c *Cluster) forEachMachine(do func(*Machine, int) error) error {
+// machineJob is an operation to run on a machine.
+type machineJob struct {
+	machine *Machine
+	// index is given to the operation. See forEachMachine.
+	index int
+}
+
+// runJobs runs do on each job, with at most c.parallel jobs running
+// concurrently. All jobs are run, whether some fail or not, and the errors are
+// returned, in job order, as MachineErrors.
+func (c *Cluster) runJobs(jobs []machineJob, do func(*Machine, int) error) error {
+	parallel := c.parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	errs := make([]error, len(jobs))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = do(jobs[i].machine, jobs[i].index)
+		}(i)
+	}
+	wg.Wait()
+
+	var machineErrs MachineErrors
+	for i, err := range errs {
+		if err != nil {
+			machineErrs = append(machineErrs, &MachineError{
+				Machine: jobs[i].machine.name,
+				Err:     err,
+			})
+		}
+	}
+	if len(machineErrs) > 0 {
+		return machineErrs
+	}
+	return nil
+}
+
+// machineJobs returns a job for each machine of the cluster.
+func (c *Cluster) machineJobs() []machineJob {
+	var jobs []machineJob
 	machineIndex := 0
 	for _, template := range c.spec.Machines {
 		for i := 0; i < template.Count; i++ {
 			// machine name indexed with i
 			machine := c.machine(&template.Spec, i)
 			// but to prevent port collision, we use machineIndex for the real machine creation
-			if err := do(machine, machineIndex); err != nil {
-				return err
-			}
+			jobs = append(jobs, machineJob{machine: machine, index: machineIndex})
 			machineIndex++
 		}
 	}
-	return nil
+	return jobs
+}
+
+func (c *Cluster) forEachMachine(do func(*Machine, int) error) error {
+	return c.runJobs(c.machineJobs(), do)
 }
 
 func (c *Cluster) forSpecificMachines(do func(*Machine, int) error, machineNames []string) error {
@@ -136,14 +239,13 @@ func (c *Cluster) forSpecificMachines(do func(*Machine, int) error, machineNames
 	for _, machine := range machineNames {
 		machineToStart[machine] = false
 	}
+	var jobs []machineJob
 	for _, template := range c.spec.Machines {
 		for i := 0; i < template.Count; i++ {
 			machine := c.machine(&template.Spec, i)
 			_, ok := machineToStart[machine.name]
 			if ok {
-				if err := do(machine, i); err != nil {
-					return err
-				}
+				jobs = append(jobs, machineJob{machine: machine, index: i})
 				machineToStart[machine.name] = true
 			}
 		}
@@ -154,7 +256,7 @@ func (c *Cluster) forSpecificMachines(do func(*Machine, int) error, machineNames
 			log.Warnf("machine %v does not exist", key)
 		}
 	}
-	return nil
+	return c.runJobs(jobs, do)
 }
 
 func (c *Cluster) ensureSSHKey() error {
@@ -167,24 +269,9 @@ func (c *Cluster) ensureSSHKey() error {
 	}
 
 	log.Infof("Creating SSH key: %s ...", path)
-	return run(
-		"ssh-keygen", "-q",
-		"-t", "rsa",
-		"-b", "4096",
-		"-C", f("%s@footloose.mail", c.spec.Cluster.Name),
-		"-f", path,
-		"-N", "",
-	)
+	return generateSSHKey(c.spec.Cluster.KeyType, path, f("%s@footloose.mail", c.spec.Cluster.Name))
 }
 
-const initScript = `
-set -e
-rm -f /run/nologin
-sshdir=/root/.ssh
-mkdir $sshdir; chmod 700 $sshdir
-touch $sshdir/authorized_keys; chmod 600 $sshdir/authorized_keys
-`
-
 func (c *Cluster) publicKey(machine *Machine) ([]byte, error) {
 	// Prefer the machine public key over the cluster-wide key.
 	if machine.spec.PublicKey != "" && c.keyStore != nil {
@@ -196,7 +283,11 @@ func (c *Cluster) publicKey(machine *Machine) ([]byte, error) {
 		return data, err
 	}
 
-	// Cluster global key
+	return c.clusterPublicKey()
+}
+
+// clusterPublicKey returns the cluster global public key.
+func (c *Cluster) clusterPublicKey() ([]byte, error) {
 	if c.spec.Cluster.PrivateKey == "" {
 		return nil, errors.New("no SSH key provided")
 	}
@@ -218,18 +309,19 @@ func (c *Cluster) CreateMachine(machine *Machine, i int) error {
 	}
 
 	// Start the container.
-	log.Infof("Creating machine: %s ...", name)
+	machine.logger().Infof("Creating machine: %s ...", name)
 
 	if machine.IsCreated() {
-		log.Infof("Machine %s is already created...", name)
+		machine.logger().Infof("Machine %s is already created...", name)
 		return nil
 	}
 
-	cmd := "/sbin/init"
-	if machine.spec.Cmd != "" {
-		cmd = machine.spec.Cmd
+	if err := c.runMachineHostHooks(machine, hookHostPreCreate); err != nil {
+		return err
 	}
 
+	cmd := machineCommand(machine.spec)
+
 	if machine.IsIgnite() {
 		pubKeyPath := c.spec.Cluster.PrivateKey + ".pub"
 		if !filepath.IsAbs(pubKeyPath) {
@@ -240,14 +332,19 @@ func (c *Cluster) CreateMachine(machine *Machine, i int) error {
 			pubKeyPath = filepath.Join(wd, pubKeyPath)
 		}
 
-		if _, err := ignite.Create(machine.name, machine.spec, pubKeyPath); err != nil {
+		if _, err := ignite.Create(machine.name, machine.spec, pubKeyPath, i); err != nil {
 			return err
 		}
 	} else {
 		runArgs := c.createMachineRunArgs(machine, name, i)
-		_, err := docker.Create(machine.spec.Image,
+		labels, err := specLabels(machine.spec)
+		if err != nil {
+			return err
+		}
+		runArgs = append(runArgs, labels...)
+		_, err = docker.Create(c.machineImage(machine),
 			runArgs,
-			[]string{cmd},
+			containerCommand(cmd),
 		)
 		if err != nil {
 			return err
@@ -255,7 +352,7 @@ func (c *Cluster) CreateMachine(machine *Machine, i int) error {
 
 		if len(machine.spec.Networks) > 1 {
 			for _, network := range machine.spec.Networks[1:] {
-				log.Infof("Connecting %s to the %s network...", name, network)
+				machine.logger().Infof("Connecting %s to the %s network...", name, network)
 				if network == "bridge" {
 					if err := docker.ConnectNetwork(name, network); err != nil {
 						return err
@@ -273,22 +370,49 @@ func (c *Cluster) CreateMachine(machine *Machine, i int) error {
 		}
 
 		// Initial provisioning.
-		if err := containerRunShell(name, initScript); err != nil {
+		if err := containerRunShell(name, initScript(publicKey)); err != nil {
+			return err
+		}
+	}
+
+	// Machines created from a snapshot have already been provisioned.
+	provision := c.snapshot == nil
+	if provision {
+		if err := applyUserData(machine); err != nil {
 			return err
 		}
-		if err := copy(name, publicKey, "/root/.ssh/authorized_keys"); err != nil {
+		if err := c.provisionUsers(machine); err != nil {
 			return err
 		}
 	}
 
+	// Record the machine host keys so we can verify them when connecting.
+	if err := c.updateHostKeys(machine); err != nil {
+		machine.logger().Warnf("Could not collect the SSH host keys of %s: %v", name, err)
+	}
+
+	if err := c.recordMachine(machine); err != nil {
+		machine.logger().Warnf("Could not record machine %s in the cluster state: %v", name, err)
+	}
+
+	if !provision {
+		return nil
+	}
+	if err := c.runMachineHooks(machine, hookMachinePostCreate); err != nil {
+		return err
+	}
+	if err := c.runMachineHostHooks(machine, hookHostPostCreate); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 func (c *Cluster) createMachineRunArgs(machine *Machine, name string, i int) []string {
 	runArgs := []string{
 		"-it",
-		"--label", "works.weave.owner=footloose",
-		"--label", "works.weave.cluster=" + c.spec.Cluster.Name,
+		"--label", ownerLabel + "=footloose",
+		"--label", clusterLabel + "=" + c.spec.Cluster.Name,
 		"--name", name,
 		"--hostname", machine.Hostname(),
 		"--tmpfs", "/run",
@@ -297,6 +421,14 @@ func (c *Cluster) createMachineRunArgs(machine *Machine, name string, i int) []s
 		"-v", "/sys/fs/cgroup:/sys/fs/cgroup:ro",
 	}
 
+	if c.configPath != "" {
+		runArgs = append(runArgs, "--label", configLabel+"="+c.configPath)
+	}
+	runArgs = append(runArgs, "--label", createdLabel+"="+time.Now().UTC().Format(time.RFC3339))
+	if c.spec.Cluster.TTL != "" {
+		runArgs = append(runArgs, "--label", ttlLabel+"="+c.spec.Cluster.TTL)
+	}
+
 	for _, volume := range machine.spec.Volumes {
 		mount := f("type=%s", volume.Type)
 		if volume.Source != "" {
@@ -340,37 +472,209 @@ func (c *Cluster) createMachineRunArgs(machine *Machine, name string, i int) []s
 	return runArgs
 }
 
-// Create creates the cluster.
-func (c *Cluster) Create() error {
+// prepareCreate makes sure everything needed to create machines is available.
+func (c *Cluster) prepareCreate() error {
 	if err := c.ensureSSHKey(); err != nil {
 		return err
 	}
 	if err := docker.IsRunning(); err != nil {
 		return err
 	}
+	containers, err := hostContainers("label=" + clusterLabel + "=" + c.spec.Cluster.Name)
+	if err != nil {
+		return err
+	}
+	if len(containers) == 0 {
+		if err := c.clearDeadline(); err != nil {
+			return err
+		}
+	}
+	// Snapshot images are local.
+	if c.snapshot != nil {
+		return nil
+	}
+	pulled := make(map[string]bool)
 	for _, template := range c.spec.Machines {
-		if _, err := docker.PullIfNotPresent(template.Spec.Image, 2); err != nil {
+		spec := &template.Spec
+		key := spec.Image + "\x00" + spec.PullPolicy
+		if pulled[key] {
+			continue
+		}
+		pulled[key] = true
+		if err := pullImage(spec); err != nil {
 			return err
 		}
 	}
-	return c.forEachMachine(c.CreateMachine)
+	return c.checkImages()
+}
+
+// pullImage makes sure the image of spec is present locally, pulling it
+// according to its pull policy.
+func pullImage(spec *config.Machine) error {
+	switch spec.PullPolicy {
+	case config.PullAlways:
+		return docker.Pull(spec.Image, 2)
+	case config.PullNever:
+		if !docker.ImageExists(spec.Image) {
+			return errors.Errorf("image %s isn't present locally and the pull policy of the %s machines is %s: load it with 'footloose load-image ARCHIVE'",
+				spec.Image, spec.Name, config.PullNever)
+		}
+		log.Infof("Docker Image: %s present locally", spec.Image)
+		return nil
+	default:
+		_, err := docker.PullIfNotPresent(spec.Image, 2)
+		return err
+	}
+}
+
+// checkImages checks the docker images not provided by footloose can run
+// machines, to fail early with a clear message rather than while
+// provisioning machines. Images are only checked until they pass.
+func (c *Cluster) checkImages() error {
+	if c.skipImageCheck {
+		return nil
+	}
+	checks, err := loadImageChecks()
+	if err != nil {
+		return err
+	}
+	for _, template := range c.spec.Machines {
+		spec := &template.Spec
+		if spec.Backend == ignite.BackendName || image.IsFootlooseImage(spec.Image) {
+			continue
+		}
+		id, err := docker.ImageID(spec.Image)
+		if err != nil {
+			return err
+		}
+		if checks.passed(id, spec.Cmd) {
+			continue
+		}
+
+		log.Infof("Checking image %s ...", spec.Image)
+		report, err := image.Check(spec.Image, spec.Cmd)
+		if err != nil {
+			return err
+		}
+		var failed []string
+		for _, result := range report.Results {
+			switch {
+			case result.OK:
+			case result.Required:
+				failed = append(failed, f("%s: %s. %s", result.Requirement, result.Detail, result.Hint))
+			default:
+				log.Warnf("%s: %s: %s. %s", spec.Image, result.Requirement, result.Detail, result.Hint)
+			}
+		}
+		if len(failed) > 0 {
+			return errors.Errorf("image %s can't run footloose machines:\n  %s\nSee 'footloose image check %s', or skip this check with --skip-image-check",
+				spec.Image, strings.Join(failed, "\n  "), spec.Image)
+		}
+		checks.add(id, spec.Cmd)
+		if err := checks.save(); err != nil {
+			log.Warnf("Could not record the check of image %s: %v", spec.Image, err)
+		}
+	}
+	return nil
+}
+
+// Create creates the cluster.
+//
+// Creation is all or nothing: if a machine fails to be created, or the cluster
+// postCreate host hooks fail, the machines created by this call are deleted.
+// Machines that existed before are left alone. See SetKeepOnFailure to keep
+// them instead.
+func (c *Cluster) Create() error {
+	if err := c.prepareCreate(); err != nil {
+		return err
+	}
+	if err := c.runClusterHostHooks(hookHostPreCreate); err != nil {
+		return err
+	}
+
+	// Machines not created yet are the ones to roll back on failure. Jobs are
+	// indexed by their position in jobs.
+	jobs := c.machineJobs()
+	attempted := make([]bool, len(jobs))
+	err := c.runJobs(jobs, func(m *Machine, i int) error {
+		if !m.IsCreated() {
+			attempted[i] = true
+		}
+		return c.CreateMachine(m, i)
+	})
+	if err == nil {
+		err = c.runClusterHostHooks(hookHostPostCreate)
+	}
+	if err == nil {
+		return nil
+	}
+
+	var rollback []machineJob
+	for i, job := range jobs {
+		if attempted[i] {
+			rollback = append(rollback, job)
+		}
+	}
+	return c.rollbackCreate(rollback, err)
+}
+
+// rollbackCreate deletes the machines a failed Create attempted to create,
+// unless asked to keep them.
+func (c *Cluster) rollbackCreate(jobs []machineJob, createErr error) error {
+	var names []string
+	for _, job := range jobs {
+		if job.machine.IsCreated() {
+			names = append(names, job.machine.name)
+		}
+	}
+	if len(names) == 0 {
+		return createErr
+	}
+
+	if c.keepOnFailure {
+		log.Warnf("Cluster creation failed, keeping the machines created: %s", strings.Join(names, ", "))
+		return createErr
+	}
+
+	log.Warnf("Cluster creation failed, deleting the machines created: %s", strings.Join(names, ", "))
+	if err := c.runJobs(jobs, c.DeleteMachine); err != nil {
+		log.Errorf("Rollback failed, some machines may need to be deleted by hand: %v", err)
+		return createErr
+	}
+	log.Infof("Rolled back %d machines", len(names))
+	return createErr
 }
 
 // DeleteMachine remove a Machine from the cluster.
 func (c *Cluster) DeleteMachine(machine *Machine, i int) error {
+	if err := c.deleteMachine(machine); err != nil {
+		return err
+	}
+	// The machine is only forgotten once it's gone.
+	if err := c.forgetMachine(machine); err != nil {
+		machine.logger().Warnf("Could not remove machine %s from the cluster state: %v", machine.ContainerName(), err)
+	}
+	return nil
+}
+
+func (c *Cluster) deleteMachine(machine *Machine) error {
 	name := machine.ContainerName()
 	if !machine.IsCreated() {
-		log.Infof("Machine %s hasn't been created...", name)
+		machine.logger().Infof("Machine %s hasn't been created...", name)
 		return nil
 	}
 
+	if err := c.removeHostKeys(machine); err != nil {
+		return err
+	}
+
 	if machine.IsIgnite() {
-		log.Infof("Deleting machine: %s ...", name)
+		machine.logger().Infof("Deleting machine: %s ...", name)
 		return ignite.Remove(machine.name)
 	}
 
 	if machine.IsStarted() {
-		log.Infof("Machine %s is started, stopping and deleting machine...", name)
+		machine.logger().Infof("Machine %s is started, stopping and deleting machine...", name)
 		err := docker.Kill("KILL", name)
 		if err != nil {
 			return err
@@ -381,7 +685,7 @@ func (c *Cluster) DeleteMachine(machine *Machine, i int) error {
 		)
 		return cmd.Run()
 	}
-	log.Infof("Deleting machine: %s ...", name)
+	machine.logger().Infof("Deleting machine: %s ...", name)
 	cmd := exec.Command(
 		"docker", "rm", "--volumes",
 		name,
@@ -394,7 +698,16 @@ func (c *Cluster) Delete() error {
 	if err := docker.IsRunning(); err != nil {
 		return err
 	}
-	return c.forEachMachine(c.DeleteMachine)
+	// Machines removed from the configuration since their creation are deleted
+	// too.
+	jobs := c.machineJobs()
+	for _, m := range c.stateMachines() {
+		jobs = append(jobs, machineJob{machine: m, index: len(jobs)})
+	}
+	if err := c.runJobs(jobs, c.DeleteMachine); err != nil {
+		return err
+	}
+	return c.removeState()
 }
 
 // Inspect will generate information about running or stopped machines.
@@ -438,6 +751,7 @@ func (c *Cluster) gatherMachines() (machines []*Machine, err error) {
 	// Footloose has no machines running. Falling back to display
 	// cluster related data.
 	machines = c.gatherMachinesByCluster()
+	machines = append(machines, c.stateMachines()...)
 	for _, m := range machines {
 		if !m.IsCreated() {
 			continue
@@ -451,33 +765,7 @@ func (c *Cluster) gatherMachines() (machines []*Machine, err error) {
 			return machines, err
 		}
 
-		// Set Ports
-		ports := make([]config.PortMapping, 0)
-		for k, v := range inspect.NetworkSettings.Ports {
-			if len(v) < 1 {
-				continue
-			}
-			p := config.PortMapping{}
-			hostPort, _ := strconv.Atoi(v[0].HostPort)
-			p.HostPort = uint16(hostPort)
-			p.ContainerPort = uint16(k.Int())
-			p.Address = v[0].HostIP
-			ports = append(ports, p)
-		}
-		m.spec.PortMappings = ports
-		// Volumes
-		var volumes []config.Volume
-		for _, mount := range inspect.Mounts {
-			v := config.Volume{
-				Type:        string(mount.Type),
-				Source:      mount.Source,
-				Destination: mount.Destination,
-				ReadOnly:    mount.RW,
-			}
-			volumes = append(volumes, v)
-		}
-		m.spec.Volumes = volumes
-		m.spec.Cmd = strings.Join(inspect.Config.Cmd, ",")
+		m.command = machineCommandFromContainer(inspect.Config.Cmd)
 		m.ip = inspect.NetworkSettings.IPAddress
 		m.runtimeNetworks = NewRuntimeNetworks(inspect.NetworkSettings.Networks)
 
@@ -499,26 +787,32 @@ func (c *Cluster) gatherMachinesByCluster() (machines []*Machine) {
 func (c *Cluster) startMachine(machine *Machine, i int) error {
 	name := machine.ContainerName()
 	if !machine.IsCreated() {
-		log.Infof("Machine %s hasn't been created...", name)
+		machine.logger().Infof("Machine %s hasn't been created...", name)
 		return nil
 	}
 	if machine.IsStarted() {
-		log.Infof("Machine %s is already started...", name)
+		machine.logger().Infof("Machine %s is already started...", name)
 		return nil
 	}
-	log.Infof("Starting machine: %s ...", name)
+	machine.logger().Infof("Starting machine: %s ...", name)
 
 	if machine.IsIgnite() {
-		return ignite.Start(name)
+		if err := ignite.Start(name); err != nil {
+			return err
+		}
+	} else {
+		// Run command while sigs.k8s.io/kind/pkg/container/docker doesn't
+		// have a start command
+		cmd := exec.Command(
+			"docker", "start",
+			name,
+		)
+		if err := cmd.Run(); err != nil {
+			return err
+		}
 	}
 
-	// Run command while sigs.k8s.io/kind/pkg/container/docker doesn't
-	// have a start command
-	cmd := exec.Command(
-		"docker", "start",
-		name,
-	)
-	return cmd.Run()
+	return c.runMachineHooks(machine, hookMachinePostStart)
 }
 
 // Start starts the machines in cluster.
@@ -541,14 +835,18 @@ func (c *Cluster) stopMachine(machine *Machine, i int) error {
 	name := machine.ContainerName()
 
 	if !machine.IsCreated() {
-		log.Infof("Machine %s hasn't been created...", name)
+		machine.logger().Infof("Machine %s hasn't been created...", name)
 		return nil
 	}
 	if !machine.IsStarted() {
-		log.Infof("Machine %s is already stopped...", name)
+		machine.logger().Infof("Machine %s is already stopped...", name)
 		return nil
 	}
-	log.Infof("Stopping machine: %s ...", name)
+	if err := c.runMachineHooks(machine, hookMachinePreStop); err != nil {
+		return err
+	}
+
+	machine.logger().Infof("Stopping machine: %s ...", name)
 
 	// Run command while sigs.k8s.io/kind/pkg/container/docker doesn't
 	// have a start command
@@ -634,6 +932,12 @@ func (c *Cluster) machineFromHostname(hostname string) (*Machine, error) {
 			}
 		}
 	}
+	// Machines removed from the configuration since their creation.
+	for _, m := range c.stateMachines() {
+		if m.hostname == hostname {
+			return m, nil
+		}
+	}
 	return nil, fmt.Errorf("%s: invalid machine hostname", hostname)
 }
 
@@ -665,10 +969,18 @@ func (c *Cluster) SSH(nodename string, username string, remoteArgs ...string) er
 	if mapping.Address != "" {
 		remote = mapping.Address
 	}
+	if err := c.ensureHostKeys(machine); err != nil {
+		return err
+	}
+	knownHosts, err := c.knownHostsPath()
+	if err != nil {
+		return err
+	}
 	path, _ := homedir.Expand(c.spec.Cluster.PrivateKey)
 	args := []string{
-		"-o", "UserKnownHostsFile=/dev/null",
-		"-o", "StrictHostKeyChecking=no",
+		"-o", "UserKnownHostsFile=" + knownHosts,
+		"-o", "StrictHostKeyChecking=yes",
+		"-o", "HostKeyAlias=" + hostKeyAlias(machine),
 		"-o", "IdentitiesOnly=yes",
 		"-i", path,
 		"-p", f("%d", hostPort),
@@ -0,0 +1,200 @@
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+
+	"github.com/weaveworks/footloose/pkg/config"
+	"github.com/weaveworks/footloose/pkg/docker"
+)
+
+// ClusterSummary describes a cluster found on the host.
+type ClusterSummary struct {
+	// Name is the cluster name.
+	Name string `json:"name"`
+	// Config is the configuration file the cluster was created from, if known.
+	Config string `json:"config,omitempty"`
+	// Orphan is true when the configuration file the cluster was created from
+	// doesn't exist or doesn't describe the cluster any more.
+	Orphan bool `json:"orphan"`
+	// Machines is the number of machines of the cluster.
+	Machines int `json:"machines"`
+	// Running is the number of running machines.
+	Running int `json:"running"`
+	// Stopped is the number of machines not running.
+	Stopped int `json:"stopped"`
+}
+
+// hostContainer is a footloose container found on the host.
+type hostContainer struct {
+	name    string
+	state   string
+	cluster string
+	config  string
+	created string
+	ttl     string
+}
+
+// hostContainers lists the containers created by footloose on the host.
+func hostContainers(filters ...string) ([]hostContainer, error) {
+	format := f("{{.Names}}\t{{.State}}\t{{.Label %q}}\t{{.Label %q}}\t{{.Label %q}}\t{{.Label %q}}",
+		clusterLabel, configLabel, createdLabel, ttlLabel)
+	filters = append([]string{"label=" + ownerLabel + "=footloose"}, filters...)
+	lines, err := docker.List(format, filters...)
+	if err != nil {
+		return nil, err
+	}
+	var containers []hostContainer
+	for _, line := range lines {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 6 {
+			continue
+		}
+		containers = append(containers, hostContainer{
+			name:    fields[0],
+			state:   fields[1],
+			cluster: fields[2],
+			config:  fields[3],
+			created: fields[4],
+			ttl:     fields[5],
+		})
+	}
+	return containers, nil
+}
+
+// isOrphan returns whether the configuration file at path doesn't describe the
+// cluster name any more. Clusters created before footloose recorded their
+// configuration file are never considered orphans.
+func isOrphan(name, path string) bool {
+	if path == "" {
+		return false
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return true
+	}
+	if err != nil {
+		return false
+	}
+	spec := config.Config{}
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		// Being edited maybe, don't jump to conclusions.
+		return false
+	}
+	return spec.Cluster.Name != name
+}
+
+// ListClusters lists the clusters created by footloose on the host, sorted by
+// name. Only docker machines are taken into account.
+func ListClusters() ([]*ClusterSummary, error) {
+	if err := docker.IsRunning(); err != nil {
+		return nil, err
+	}
+	containers, err := hostContainers()
+	if err != nil {
+		return nil, err
+	}
+
+	clusters := make(map[string]*ClusterSummary)
+	var names []string
+	for _, container := range containers {
+		summary, ok := clusters[container.cluster]
+		if !ok {
+			summary = &ClusterSummary{Name: container.cluster}
+			clusters[container.cluster] = summary
+			names = append(names, container.cluster)
+		}
+		if summary.Config == "" {
+			summary.Config = container.config
+		}
+		summary.Machines++
+		if container.state == "running" {
+			summary.Running++
+		} else {
+			summary.Stopped++
+		}
+	}
+
+	sort.Strings(names)
+	var summaries []*ClusterSummary
+	for _, name := range names {
+		summary := clusters[name]
+		summary.Orphan = isOrphan(summary.Name, summary.Config)
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+// DeleteByName deletes the machines of the cluster name, found by label. No
+// configuration file is needed. It returns an error if the cluster doesn't
+// have any machine.
+func DeleteByName(name string, parallel int) error {
+	if err := docker.IsRunning(); err != nil {
+		return err
+	}
+	containers, err := hostContainers("label=" + clusterLabel + "=" + name)
+	if err != nil {
+		return err
+	}
+	if len(containers) == 0 {
+		return errors.Errorf("no machine found for cluster %s", name)
+	}
+
+	c := &Cluster{
+		spec: config.Config{
+			Cluster: config.Cluster{Name: name},
+		},
+		parallel: parallel,
+	}
+	// The recorded state knows the cluster SSH key, needed to clean up the
+	// machines host keys, and the machines hostnames.
+	hostnames := make(map[string]string)
+	if state, _ := LoadState(name); state != nil {
+		c.spec.Cluster.PrivateKey = state.PrivateKey
+		for _, m := range state.Machines {
+			hostnames[m.Name] = m.Hostname
+		}
+	}
+	var jobs []machineJob
+	for i, container := range containers {
+		hostname, ok := hostnames[container.name]
+		if !ok {
+			hostname = container.name
+		}
+		m := &Machine{
+			spec:     &config.Machine{},
+			name:     container.name,
+			hostname: hostname,
+		}
+		jobs = append(jobs, machineJob{machine: m, index: i})
+	}
+	if err := c.runJobs(jobs, c.DeleteMachine); err != nil {
+		return err
+	}
+	return c.removeState()
+}
+
+// DeleteOrphans deletes the clusters which configuration file doesn't exist or
+// doesn't describe them any more. It returns the names of the deleted clusters.
+func DeleteOrphans(parallel int) ([]string, error) {
+	clusters, err := ListClusters()
+	if err != nil {
+		return nil, err
+	}
+	var deleted []string
+	for _, cluster := range clusters {
+		if !cluster.Orphan {
+			continue
+		}
+		if err := DeleteByName(cluster.Name, parallel); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, cluster.Name)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,27 @@
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsOrphan(t *testing.T) {
+	dir, err := ioutil.TempDir("", "footloose-orphan")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "footloose.yaml")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("cluster:\n  name: cluster\n"), 0644))
+
+	assert.False(t, isOrphan("cluster", ""), "unknown config")
+	assert.False(t, isOrphan("cluster", path), "config describing the cluster")
+	assert.True(t, isOrphan("renamed", path), "config describing another cluster")
+	assert.True(t, isOrphan("cluster", filepath.Join(dir, "missing.yaml")), "missing config")
+
+	assert.NoError(t, ioutil.WriteFile(path, []byte("cluster: [\n"), 0644))
+	assert.False(t, isOrphan("cluster", path), "invalid config")
+}
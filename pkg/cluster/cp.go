@@ -0,0 +1,179 @@
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// CopyOptions controls how files are copied to and from machines.
+type CopyOptions struct {
+	// Recursive allows copying directories.
+	Recursive bool
+	// Owner, if not empty, is the "user[:group]" owning the copied files at the
+	// destination.
+	Owner string
+	// Mode, if not empty, is the mode, in a form chmod understands, of the
+	// copied files at the destination.
+	Mode string
+}
+
+// copyPath is either a path on the host or a path inside a machine.
+type copyPath struct {
+	hostname string // empty for host paths.
+	path     string
+}
+
+func (p *copyPath) isMachine() bool {
+	return p.hostname != ""
+}
+
+func (p *copyPath) String() string {
+	if p.isMachine() {
+		return p.hostname + ":" + p.path
+	}
+	return p.path
+}
+
+// parseCopyPath parses a cp argument. Machine paths are of the form
+// hostname:path, anything else is considered a host path.
+func parseCopyPath(s string) copyPath {
+	i := strings.Index(s, ":")
+	if i < 1 || strings.Contains(s[:i], "/") {
+		return copyPath{path: s}
+	}
+	return copyPath{hostname: s[:i], path: s[i+1:]}
+}
+
+// isDir returns whether p is a directory.
+func (c *Cluster) isDir(p copyPath) bool {
+	if !p.isMachine() {
+		info, err := os.Stat(p.path)
+		return err == nil && info.IsDir()
+	}
+	machine, err := c.machineFromHostname(p.hostname)
+	if err != nil {
+		return false
+	}
+	return machine.cmder().Command("test", "-d", p.path).Run() == nil
+}
+
+// Copy copies src to each of the dsts. Paths inside machines are of the form
+// hostname:path, at least one of src and each dst has to be a machine path.
+func (c *Cluster) Copy(src string, dsts []string, opts CopyOptions) error {
+	source := parseCopyPath(src)
+	if source.isMachine() {
+		if _, err := c.machineFromHostname(source.hostname); err != nil {
+			return err
+		}
+	}
+	if c.isDir(source) && !opts.Recursive {
+		return errors.Errorf("%s is a directory, use recursive mode to copy it", src)
+	}
+
+	for _, dst := range dsts {
+		dest := parseCopyPath(dst)
+		if err := c.copy(source, dest, opts); err != nil {
+			return errors.Wrapf(err, "copy %s to %s", source.String(), dest.String())
+		}
+	}
+	return nil
+}
+
+func (c *Cluster) copy(src, dst copyPath, opts CopyOptions) error {
+	if !src.isMachine() && !dst.isMachine() {
+		return errors.New("either the source or the destination needs to be a machine path")
+	}
+
+	// When copying into an existing directory, the source ends up inside that
+	// directory.
+	final := dst
+	if c.isDir(dst) {
+		if dst.isMachine() {
+			final.path = path.Join(dst.path, path.Base(src.path))
+		} else {
+			final.path = filepath.Join(dst.path, filepath.Base(src.path))
+		}
+	}
+
+	switch {
+	case src.isMachine() && dst.isMachine():
+		// Go through a temporary directory on the host.
+		tmp, err := ioutil.TempDir("", "footloose-cp")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmp)
+		hostPath := filepath.Join(tmp, path.Base(src.path))
+		if err := c.copyFrom(src, hostPath); err != nil {
+			return err
+		}
+		if err := c.copyTo(hostPath, dst); err != nil {
+			return err
+		}
+	case src.isMachine():
+		if err := c.copyFrom(src, dst.path); err != nil {
+			return err
+		}
+	default:
+		if err := c.copyTo(src.path, dst); err != nil {
+			return err
+		}
+	}
+
+	return c.setOwnership(final, opts)
+}
+
+func (c *Cluster) copyFrom(src copyPath, hostPath string) error {
+	machine, err := c.machineFromHostname(src.hostname)
+	if err != nil {
+		return err
+	}
+	log.Infof("Copying %s to %s ...", src.String(), hostPath)
+	return machine.CopyFrom(src.path, hostPath)
+}
+
+func (c *Cluster) copyTo(hostPath string, dst copyPath) error {
+	machine, err := c.machineFromHostname(dst.hostname)
+	if err != nil {
+		return err
+	}
+	log.Infof("Copying %s to %s ...", hostPath, dst.String())
+	return machine.CopyTo(hostPath, dst.path)
+}
+
+// setOwnership applies the owner and mode copy options to p.
+func (c *Cluster) setOwnership(p copyPath, opts CopyOptions) error {
+	var commands [][]string
+	if opts.Owner != "" {
+		commands = append(commands, []string{"chown", opts.Owner, p.path})
+	}
+	if opts.Mode != "" {
+		commands = append(commands, []string{"chmod", opts.Mode, p.path})
+	}
+
+	for _, command := range commands {
+		if opts.Recursive {
+			command = append([]string{command[0], "-R"}, command[1:]...)
+		}
+		if !p.isMachine() {
+			if err := run(command[0], command[1:]...); err != nil {
+				return err
+			}
+			continue
+		}
+		machine, err := c.machineFromHostname(p.hostname)
+		if err != nil {
+			return err
+		}
+		if err := machineRun(machine, command[0], command[1:]...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCopyPath(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected copyPath
+	}{
+		{"node0:/etc/hosts", copyPath{hostname: "node0", path: "/etc/hosts"}},
+		{"node0:", copyPath{hostname: "node0", path: ""}},
+		{"./local", copyPath{path: "./local"}},
+		{"/tmp/a:b", copyPath{path: "/tmp/a:b"}},
+		{":foo", copyPath{path: ":foo"}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, parseCopyPath(test.input))
+	}
+}
@@ -0,0 +1,137 @@
+package cluster
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/weaveworks/footloose/pkg/exec"
+)
+
+// diagnosticCommand is a command run inside machines, which output is saved in
+// the diagnostics bundle.
+type diagnosticCommand struct {
+	file   string
+	script string
+}
+
+// diagnosticCommands are run in running machines. Commands missing from the
+// machine image are reported in the output file.
+var diagnosticCommands = []diagnosticCommand{
+	{"journal.log", "journalctl --no-pager --output short-iso"},
+	{"dmesg.log", "dmesg"},
+	{"systemctl-status.log", "systemctl status --all --no-pager"},
+	{"network.log", `for cmd in "ip addr" "ip route" "cat /etc/hosts" "cat /etc/resolv.conf"; do echo "# $cmd"; $cmd; echo; done`},
+}
+
+// diagnosticsRecorder writes the files of a machine diagnostics directory,
+// recording failures in errors.log instead of failing.
+type diagnosticsRecorder struct {
+	dir  string
+	errs []string
+}
+
+func (r *diagnosticsRecorder) fail(file string, err error) {
+	r.errs = append(r.errs, f("%s: %v", file, err))
+}
+
+func (r *diagnosticsRecorder) write(file string, data []byte) {
+	if err := ioutil.WriteFile(filepath.Join(r.dir, file), data, 0644); err != nil {
+		r.fail(file, err)
+	}
+}
+
+// run saves the output of cmd in file.
+func (r *diagnosticsRecorder) run(file string, cmd exec.Cmd) {
+	out, err := os.Create(filepath.Join(r.dir, file))
+	if err != nil {
+		r.fail(file, err)
+		return
+	}
+	defer out.Close()
+	cmd.SetStdout(out)
+	cmd.SetStderr(out)
+	if err := cmd.Run(); err != nil {
+		r.fail(file, err)
+	}
+}
+
+func (r *diagnosticsRecorder) close() error {
+	if len(r.errs) == 0 {
+		return nil
+	}
+	return ioutil.WriteFile(filepath.Join(r.dir, "errors.log"), []byte(strings.Join(r.errs, "\n")+"\n"), 0644)
+}
+
+// machineDiagnostics gathers the diagnostics of m in dir.
+func machineDiagnostics(m *Machine, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	r := &diagnosticsRecorder{dir: dir}
+
+	status, err := json.MarshalIndent(m.Status(), "", "  ")
+	if err != nil {
+		r.fail("status.json", err)
+	} else {
+		r.write("status.json", status)
+	}
+
+	if !m.IsCreated() {
+		return r.close()
+	}
+
+	if m.IsIgnite() {
+		r.run("inspect.json", exec.Command("ignite", "inspect", "vm", m.name))
+		r.run("console.log", exec.Command("ignite", "logs", m.name))
+	} else {
+		r.run("inspect.json", exec.Command("docker", "inspect", m.name))
+		r.run("container.log", exec.Command("docker", "logs", m.name))
+	}
+
+	if !m.IsStarted() {
+		return r.close()
+	}
+	for _, c := range diagnosticCommands {
+		r.run(c.file, m.cmder().Command("/bin/sh", "-c", c.script))
+	}
+	return r.close()
+}
+
+// ExportLogs gathers diagnostics of the cluster machines in dir, one directory
+// per machine hostname, along with the cluster configuration. Machines that
+// are stopped or only partially created are exported with what can be
+// gathered from them. Failures to gather a piece of information are recorded
+// in the errors.log file of the machine directory.
+func (c *Cluster) ExportLogs(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	// The configuration file may be gone when using the recorded state.
+	config := filepath.Join(dir, "footloose.yaml")
+	if c.configPath == "" || copyFile(c.configPath, config) != nil {
+		if err := c.Save(config); err != nil {
+			return errors.Wrap(err, "configuration")
+		}
+	}
+
+	machines, err := c.gatherMachines()
+	if err != nil {
+		// Inspecting a half-created machine can fail, keep going with what
+		// we have.
+		machines = append(c.gatherMachinesByCluster(), c.stateMachines()...)
+	}
+	var jobs []machineJob
+	for i, m := range machines {
+		jobs = append(jobs, machineJob{machine: m, index: i})
+	}
+	return c.runJobs(jobs, func(m *Machine, _ int) error {
+		m.logger().Infof("Gathering diagnostics of machine %s ...", m.name)
+		return machineDiagnostics(m, filepath.Join(dir, m.hostname))
+	})
+}
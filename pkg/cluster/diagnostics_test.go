@@ -0,0 +1,59 @@
+package cluster
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weaveworks/footloose/pkg/exec"
+)
+
+func TestDiagnosticsRecorder(t *testing.T) {
+	dir, err := ioutil.TempDir("", "footloose-diagnostics")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	r := &diagnosticsRecorder{dir: dir}
+	r.run("ok.log", exec.Command("/bin/sh", "-c", "echo ok"))
+	r.run("failed.log", exec.Command("/bin/sh", "-c", "echo partial; exit 3"))
+	assert.NoError(t, r.close())
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "ok.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, "ok\n", string(data))
+	data, err = ioutil.ReadFile(filepath.Join(dir, "failed.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, "partial\n", string(data))
+	data, err = ioutil.ReadFile(filepath.Join(dir, "errors.log"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "failed.log: ")
+	assert.NotContains(t, string(data), "ok.log")
+}
+
+func TestExportLogsNotCreated(t *testing.T) {
+	defer withHome(t)()
+	dir, err := ioutil.TempDir("", "footloose-diagnostics")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := newTestCluster(t, 2)
+	assert.NoError(t, c.ExportLogs(dir))
+
+	_, err = os.Stat(filepath.Join(dir, "footloose.yaml"))
+	assert.NoError(t, err)
+	for _, hostname := range []string{"group0-node0", "group0-node1"} {
+		data, err := ioutil.ReadFile(filepath.Join(dir, hostname, "status.json"))
+		assert.NoError(t, err)
+		status := MachineStatus{}
+		assert.NoError(t, json.Unmarshal(data, &status))
+		assert.Equal(t, hostname, status.Hostname)
+		assert.Equal(t, NotCreated, status.State)
+
+		_, err = os.Stat(filepath.Join(dir, hostname, "inspect.json"))
+		assert.True(t, os.IsNotExist(err))
+	}
+}
@@ -0,0 +1,31 @@
+package cluster
+
+import (
+	"strings"
+)
+
+// MachineError is an error that happened while operating on a machine.
+type MachineError struct {
+	// Machine is the name of the machine the error is about.
+	Machine string
+	Err     error
+}
+
+func (e *MachineError) Error() string {
+	return e.Machine + ": " + e.Err.Error()
+}
+
+// MachineErrors is the list of errors that happened while operating on a set
+// of machines.
+type MachineErrors []*MachineError
+
+func (e MachineErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return f("%d machines failed:\n  %s", len(e), strings.Join(msgs, "\n  "))
+}
@@ -0,0 +1,247 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/weaveworks/footloose/pkg/docker"
+)
+
+// Event types.
+const (
+	EventCreated = "created"
+	EventStarted = "started"
+	EventStopped = "stopped"
+	EventDied    = "died"
+	EventOOM     = "oom"
+	EventDeleted = "deleted"
+)
+
+// dockerEventTypes maps docker container actions to event types. Other
+// actions are ignored.
+var dockerEventTypes = map[string]string{
+	"create":  EventCreated,
+	"start":   EventStarted,
+	"stop":    EventStopped,
+	"die":     EventDied,
+	"oom":     EventOOM,
+	"destroy": EventDeleted,
+}
+
+// Event is a state transition of a cluster machine.
+type Event struct {
+	// Time is when the transition happened.
+	Time time.Time `json:"time"`
+	// Type is one of the Event* constants.
+	Type string `json:"type"`
+	// Machine is the machine hostname.
+	Machine string `json:"machine"`
+	// Container is the name of the machine container or VM.
+	Container string `json:"container"`
+	// ExitCode is the exit code of the machine init process, for died events.
+	ExitCode *int `json:"exitCode,omitempty"`
+}
+
+// EventPollInterval is how often ignite machines are polled for state
+// transitions, ignite not reporting events.
+var EventPollInterval = 2 * time.Second
+
+// eventHostnames maps the container names of the cluster machines to their
+// hostnames.
+func (c *Cluster) eventHostnames() map[string]string {
+	hostnames := make(map[string]string)
+	for _, job := range c.machineJobs() {
+		hostnames[job.machine.name] = job.machine.hostname
+	}
+	for _, m := range c.stateMachines() {
+		hostnames[m.name] = m.hostname
+	}
+	return hostnames
+}
+
+// dockerEvent converts a docker event to a cluster event. It returns nil for
+// events footloose doesn't report.
+func dockerEvent(e *docker.Event, hostnames map[string]string) *Event {
+	if e.Type != "container" {
+		return nil
+	}
+	eventType, ok := dockerEventTypes[e.Action]
+	if !ok {
+		return nil
+	}
+	name := e.Actor.Attributes["name"]
+	hostname, ok := hostnames[name]
+	if !ok {
+		// Machines created after the stream started with an outdated
+		// configuration, or by another cluster with the same name.
+		hostname = name
+	}
+	event := &Event{
+		Time:      time.Unix(0, e.TimeNano),
+		Type:      eventType,
+		Machine:   hostname,
+		Container: name,
+	}
+	if eventType == EventDied {
+		if code, err := strconv.Atoi(e.Actor.Attributes["exitCode"]); err == nil {
+			event.ExitCode = &code
+		}
+	}
+	return event
+}
+
+// igniteState is the state of an ignite machine, as polled.
+type igniteState struct {
+	created bool
+	started bool
+}
+
+func pollIgniteState(m *Machine) igniteState {
+	s := igniteState{created: m.IsCreated()}
+	s.started = s.created && m.IsStarted()
+	return s
+}
+
+// igniteEvents returns the events of a transition between two polled states.
+func igniteEvents(m *Machine, from, to igniteState, now time.Time) []*Event {
+	event := func(eventType string) *Event {
+		return &Event{Time: now, Type: eventType, Machine: m.hostname, Container: m.name}
+	}
+	var events []*Event
+	if !from.created && to.created {
+		events = append(events, event(EventCreated))
+	}
+	if !from.started && to.started {
+		events = append(events, event(EventStarted))
+	}
+	if from.started && !to.started {
+		events = append(events, event(EventStopped))
+	}
+	if from.created && !to.created {
+		events = append(events, event(EventDeleted))
+	}
+	return events
+}
+
+// pollIgnite polls machines until ctx is done, sending their state transitions
+// to events.
+func pollIgnite(ctx context.Context, machines []*Machine, events chan<- Event) {
+	states := make([]igniteState, len(machines))
+	for i, m := range machines {
+		states[i] = pollIgniteState(m)
+	}
+	ticker := time.NewTicker(EventPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for i, m := range machines {
+				state := pollIgniteState(m)
+				for _, event := range igniteEvents(m, states[i], state, now) {
+					select {
+					case events <- *event:
+					case <-ctx.Done():
+						return
+					}
+				}
+				states[i] = state
+			}
+		}
+	}
+}
+
+// Events sends the state transitions of the cluster machines to events until
+// ctx is done or the event stream fails. Docker machines are watched through
+// docker events, filtered by the cluster label. Ignite machines are polled
+// every EventPollInterval.
+//
+// Events blocks, it's meant to be run in its own goroutine. It doesn't close
+// events.
+func (c *Cluster) Events(ctx context.Context, events chan<- Event) error {
+	var ignite []*Machine
+	dockerMachines := 0
+	for _, m := range c.gatherMachinesByCluster() {
+		if m.IsIgnite() {
+			ignite = append(ignite, m)
+		} else {
+			dockerMachines++
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if len(ignite) > 0 {
+			pollIgnite(ctx, ignite, events)
+		}
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	if dockerMachines == 0 {
+		<-ctx.Done()
+		return nil
+	}
+	hostnames := c.eventHostnames()
+	return docker.Events(ctx, func(e *docker.Event) {
+		event := dockerEvent(e, hostnames)
+		if event == nil {
+			return
+		}
+		select {
+		case events <- *event:
+		case <-ctx.Done():
+		}
+	}, "type=container", "label="+clusterLabel+"="+c.spec.Cluster.Name)
+}
+
+// EventFormatter writes events as they come.
+type EventFormatter interface {
+	FormatEvent(io.Writer, *Event) error
+}
+
+// JSONEventFormatter writes events as JSON, one per line.
+type JSONEventFormatter struct{}
+
+// FormatEvent writes e as a JSON line.
+func (JSONEventFormatter) FormatEvent(w io.Writer, e *Event) error {
+	return json.NewEncoder(w).Encode(e)
+}
+
+// TableEventFormatter writes events as table rows. Events are written as they
+// come so the columns have a fixed width.
+type TableEventFormatter struct {
+	header bool
+}
+
+func writeEventRow(w io.Writer, cols ...interface{}) error {
+	row := fmt.Sprintf("%-25s  %-20s  %-8s  %s", cols...)
+	_, err := fmt.Fprintln(w, strings.TrimRight(row, " "))
+	return err
+}
+
+// FormatEvent writes e as a table row, preceded by the table header for the
+// first event.
+func (t *TableEventFormatter) FormatEvent(w io.Writer, e *Event) error {
+	if !t.header {
+		if err := writeEventRow(w, "TIME", "MACHINE", "EVENT", "DETAILS"); err != nil {
+			return err
+		}
+		t.header = true
+	}
+	details := ""
+	if e.ExitCode != nil {
+		details = f("exit code %d", *e.ExitCode)
+	}
+	return writeEventRow(w, e.Time.Format(time.RFC3339), e.Machine, e.Type, details)
+}
@@ -0,0 +1,76 @@
+package cluster
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weaveworks/footloose/pkg/docker"
+)
+
+func newDockerEvent(action string, attributes map[string]string) *docker.Event {
+	e := &docker.Event{Type: "container", Action: action, TimeNano: 1559383200000000000}
+	e.Actor.Attributes = attributes
+	return e
+}
+
+func TestDockerEvent(t *testing.T) {
+	hostnames := map[string]string{"cluster-node0": "node0"}
+
+	event := dockerEvent(newDockerEvent("start", map[string]string{"name": "cluster-node0"}), hostnames)
+	assert.Equal(t, &Event{
+		Time:      time.Unix(1559383200, 0),
+		Type:      EventStarted,
+		Machine:   "node0",
+		Container: "cluster-node0",
+	}, event)
+
+	event = dockerEvent(newDockerEvent("die", map[string]string{"name": "cluster-node0", "exitCode": "137"}), hostnames)
+	assert.Equal(t, EventDied, event.Type)
+	if assert.NotNil(t, event.ExitCode) {
+		assert.Equal(t, 137, *event.ExitCode)
+	}
+
+	// Unknown machines are reported with their container name.
+	event = dockerEvent(newDockerEvent("oom", map[string]string{"name": "cluster-node9"}), hostnames)
+	assert.Equal(t, EventOOM, event.Type)
+	assert.Equal(t, "cluster-node9", event.Machine)
+
+	assert.Nil(t, dockerEvent(newDockerEvent("exec_start: ls", map[string]string{"name": "cluster-node0"}), hostnames))
+	network := newDockerEvent("create", nil)
+	network.Type = "network"
+	assert.Nil(t, dockerEvent(network, hostnames))
+}
+
+func TestIgniteEvents(t *testing.T) {
+	m := &Machine{name: "cluster-node0", hostname: "node0"}
+	types := func(events []*Event) []string {
+		var types []string
+		for _, e := range events {
+			types = append(types, e.Type)
+		}
+		return types
+	}
+	now := time.Now()
+	notCreated, stopped, running := igniteState{}, igniteState{created: true}, igniteState{created: true, started: true}
+
+	assert.Nil(t, types(igniteEvents(m, running, running, now)))
+	assert.Equal(t, []string{EventCreated, EventStarted}, types(igniteEvents(m, notCreated, running, now)))
+	assert.Equal(t, []string{EventStopped}, types(igniteEvents(m, running, stopped, now)))
+	assert.Equal(t, []string{EventStopped, EventDeleted}, types(igniteEvents(m, running, notCreated, now)))
+}
+
+func TestTableEventFormatter(t *testing.T) {
+	var out bytes.Buffer
+	formatter := new(TableEventFormatter)
+	when := time.Date(2019, 6, 1, 10, 0, 0, 0, time.UTC)
+	code := 1
+	assert.NoError(t, formatter.FormatEvent(&out, &Event{Time: when, Type: EventStarted, Machine: "node0"}))
+	assert.NoError(t, formatter.FormatEvent(&out, &Event{Time: when, Type: EventDied, Machine: "node0", ExitCode: &code}))
+	assert.Equal(t, `TIME                       MACHINE               EVENT     DETAILS
+2019-06-01T10:00:00Z       node0                 started
+2019-06-01T10:00:00Z       node0                 died      exit code 1
+`, out.String())
+}
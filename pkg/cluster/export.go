@@ -0,0 +1,317 @@
+package cluster
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/weaveworks/footloose/pkg/config"
+	"github.com/weaveworks/footloose/pkg/docker"
+)
+
+// Cluster archive layout:
+//
+//   footloose.yaml          cluster configuration
+//   snapshot.json           snapshot of the cluster machines
+//   keys/<key>, <key>.pub   cluster SSH key
+//   images/<hostname>.tar   machine images, as saved by docker save
+const (
+	archiveConfig   = "footloose.yaml"
+	archiveSnapshot = "snapshot.json"
+	archiveKeys     = "keys"
+	archiveImages   = "images"
+)
+
+func addFileToArchive(tw *tar.Writer, name, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    int64(info.Mode().Perm()),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, file)
+	return err
+}
+
+func addDataToArchive(tw *tar.Writer, name string, data []byte, mode int64) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    mode,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// Export snapshots the cluster machines and writes an archive with the cluster
+// configuration, its SSH key and the machine images to dest. The cluster can
+// be recreated from the archive, without access to an image registry, with
+// Import.
+func (c *Cluster) Export(dest string) error {
+	snapshot, err := c.Snapshot("export-" + time.Now().Format("20060102-150405"))
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempDir("", "footloose-export")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	tw := tar.NewWriter(out)
+
+	// Configuration, referring to the key from the archive.
+	spec := c.spec
+	var keyPath string
+	if spec.Cluster.PrivateKey != "" {
+		keyPath, err = homedir.Expand(spec.Cluster.PrivateKey)
+		if err != nil {
+			return err
+		}
+		spec.Cluster.PrivateKey = filepath.Base(keyPath)
+	}
+	data, err := yaml.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	if err := addDataToArchive(tw, archiveConfig, data, 0644); err != nil {
+		return err
+	}
+
+	data, err = json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := addDataToArchive(tw, archiveSnapshot, data, 0644); err != nil {
+		return err
+	}
+
+	if keyPath != "" {
+		for _, p := range []string{keyPath, keyPath + ".pub"} {
+			if err := addFileToArchive(tw, path.Join(archiveKeys, filepath.Base(p)), p); err != nil {
+				return errors.Wrap(err, "export SSH key")
+			}
+		}
+	}
+
+	for _, m := range snapshot.Machines {
+		imageArchive := filepath.Join(tmp, m.Hostname+".tar")
+		log.Infof("Saving image %s ...", m.Image)
+		if err := docker.Save(m.Image, imageArchive); err != nil {
+			return errors.Wrapf(err, "save image %s", m.Image)
+		}
+		if err := addFileToArchive(tw, path.Join(archiveImages, m.Hostname+".tar"), imageArchive); err != nil {
+			return err
+		}
+		os.Remove(imageArchive)
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return out.Close()
+}
+
+// copyFile copies src to dst, keeping its permissions.
+func copyFile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(dst, data, info.Mode().Perm())
+}
+
+// archiveEntryPath returns where to extract an archive entry in dir, checking
+// the entry doesn't escape dir.
+func archiveEntryPath(dir, name string) (string, error) {
+	clean := path.Clean(name)
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", errors.Errorf("invalid archive entry '%s'", name)
+	}
+	return filepath.Join(dir, filepath.FromSlash(clean)), nil
+}
+
+// importedKeyPath returns the absolute path to import the SSH key name of an
+// archive to, in dir. Export writes key names without directory, anything else
+// could escape dir.
+func importedKeyPath(dir, name string) (string, error) {
+	if name != filepath.Base(name) || name == "." || name == ".." {
+		return "", errors.Errorf("invalid private key name '%s' in archive", name)
+	}
+	return filepath.Abs(filepath.Join(dir, name))
+}
+
+func extractArchive(src, dir string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tr := tar.NewReader(in)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		dest, err := archiveEntryPath(dir, hdr.Name)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode).Perm())
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, tr); err != nil {
+			out.Close()
+			return err
+		}
+		if err := out.Close(); err != nil {
+			return err
+		}
+	}
+}
+
+// Import loads the machine images of an archive written by Export and writes
+// the cluster configuration and SSH key in dir. The returned cluster creates
+// its machines from the imported images.
+func Import(src, dir string) (*Cluster, error) {
+	tmp, err := ioutil.TempDir("", "footloose-import")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := extractArchive(src, tmp); err != nil {
+		return nil, errors.Wrapf(err, "extract %s", src)
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(tmp, archiveConfig))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid cluster archive")
+	}
+	spec := config.Config{}
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	data, err = ioutil.ReadFile(filepath.Join(tmp, archiveSnapshot))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid cluster archive")
+	}
+	snapshot := &Snapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, errors.Wrap(err, "invalid cluster archive")
+	}
+
+	configPath := filepath.Join(dir, archiveConfig)
+	if fileExists(configPath) {
+		return nil, errors.Errorf("%s already exists", configPath)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	// SSH key.
+	if spec.Cluster.PrivateKey != "" {
+		// An absolute path works both from where footloose runs and from dir,
+		// where the configuration is written.
+		keyPath, err := importedKeyPath(dir, spec.Cluster.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range []string{keyPath, keyPath + ".pub"} {
+			if fileExists(p) {
+				return nil, errors.Errorf("%s already exists", p)
+			}
+			extracted := filepath.Join(tmp, archiveKeys, filepath.Base(p))
+			if err := copyFile(extracted, p); err != nil {
+				return nil, errors.Wrap(err, "import SSH key")
+			}
+		}
+		spec.Cluster.PrivateKey = keyPath
+	}
+
+	// Machine images.
+	for _, m := range snapshot.Machines {
+		imageArchive := filepath.Join(tmp, archiveImages, m.Hostname+".tar")
+		tags, err := docker.GetArchiveTags(imageArchive)
+		if err != nil {
+			return nil, errors.Wrapf(err, "image of machine %s", m.Hostname)
+		}
+		found := false
+		for _, tag := range tags {
+			if tag == m.Image {
+				found = true
+			}
+		}
+		if !found {
+			return nil, errors.Errorf("image archive of machine %s doesn't contain %s (found %v)", m.Hostname, m.Image, tags)
+		}
+		log.Infof("Loading image %s ...", m.Image)
+		if err := docker.Load(imageArchive); err != nil {
+			return nil, errors.Wrapf(err, "load image %s", m.Image)
+		}
+	}
+
+	c, err := New(spec)
+	if err != nil {
+		return nil, err
+	}
+	if err := snapshot.save(); err != nil {
+		return nil, err
+	}
+	if err := c.SetSnapshot(snapshot); err != nil {
+		return nil, err
+	}
+	if err := c.Save(configPath); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
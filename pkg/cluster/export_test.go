@@ -0,0 +1,63 @@
+package cluster
+
+import (
+	"archive/tar"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchiveEntryPath(t *testing.T) {
+	p, err := archiveEntryPath("/tmp/x", "images/node0.tar")
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/x/images/node0.tar", p)
+
+	for _, name := range []string{"../etc/passwd", "/etc/passwd", "images/../../x"} {
+		_, err := archiveEntryPath("/tmp/x", name)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestImportedKeyPath(t *testing.T) {
+	p, err := importedKeyPath("/tmp/x", "cluster-key")
+	assert.NoError(t, err)
+	assert.Equal(t, "/tmp/x/cluster-key", p)
+
+	p, err = importedKeyPath("out", "cluster-key")
+	assert.NoError(t, err)
+	assert.True(t, filepath.IsAbs(p))
+	assert.Equal(t, "cluster-key", filepath.Base(p))
+
+	for _, name := range []string{"../../.ssh/id_rsa", "/root/.ssh/id_rsa", "keys/cluster-key", ".."} {
+		_, err := importedKeyPath("/tmp/x", name)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestExtractArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "footloose-archive")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, "cluster.tar")
+	out, err := os.Create(archive)
+	assert.NoError(t, err)
+	tw := tar.NewWriter(out)
+	assert.NoError(t, addDataToArchive(tw, archiveConfig, []byte("cluster: {}\n"), 0644))
+	assert.NoError(t, addDataToArchive(tw, "keys/cluster-key", []byte("private"), 0600))
+	assert.NoError(t, tw.Close())
+	assert.NoError(t, out.Close())
+
+	extracted := filepath.Join(dir, "extracted")
+	assert.NoError(t, extractArchive(archive, extracted))
+
+	data, err := ioutil.ReadFile(filepath.Join(extracted, archiveConfig))
+	assert.NoError(t, err)
+	assert.Equal(t, "cluster: {}\n", string(data))
+	info, err := os.Stat(filepath.Join(extracted, "keys", "cluster-key"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
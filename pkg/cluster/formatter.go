@@ -48,6 +48,7 @@ type MachineStatus struct {
 	Image           string            `json:"image"`
 	Command         string            `json:"cmd"`
 	IP              string            `json:"ip"`
+	Ready           bool              `json:"ready"`
 	RuntimeNetworks []*RuntimeNetwork `json:"runtimeNetworks,omitempty"`
 }
 
@@ -0,0 +1,149 @@
+package cluster
+
+import (
+	"context"
+	"os"
+	osexec "os/exec"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/weaveworks/footloose/pkg/config"
+	"github.com/weaveworks/footloose/pkg/exec"
+)
+
+// Hook points.
+const (
+	hookHostPreCreate     = "host preCreate"
+	hookHostPostCreate    = "host postCreate"
+	hookMachinePostCreate = "machine postCreate"
+	hookMachinePostStart  = "machine postStart"
+	hookMachinePreStop    = "machine preStop"
+)
+
+// hooks returns the hooks to run at point, from a hooks definition.
+func hooks(h *config.Hooks, point string) []config.Hook {
+	if h == nil {
+		return nil
+	}
+	switch point {
+	case hookHostPreCreate:
+		return h.Host.PreCreate
+	case hookHostPostCreate:
+		return h.Host.PostCreate
+	case hookMachinePostCreate:
+		return h.Machine.PostCreate
+	case hookMachinePostStart:
+		return h.Machine.PostStart
+	case hookMachinePreStop:
+		return h.Machine.PreStop
+	}
+	return nil
+}
+
+// hookEnv returns the environment variables describing the cluster, and the
+// machine when m isn't nil, given to hooks.
+func (c *Cluster) hookEnv(m *Machine) []string {
+	env := []string{
+		"FOOTLOOSE_CLUSTER=" + c.spec.Cluster.Name,
+	}
+	if c.configPath != "" {
+		env = append(env, "FOOTLOOSE_CONFIG="+c.configPath)
+	}
+	if m != nil {
+		env = append(env,
+			"FOOTLOOSE_MACHINE="+m.hostname,
+			"FOOTLOOSE_CONTAINER="+m.name,
+		)
+	}
+	return env
+}
+
+// runHostHook runs a hook on the host. The hook runs in its own process group
+// so the processes it spawns are killed with it when it times out.
+func runHostHook(hook *config.Hook, env []string) error {
+	timeout := hook.TimeoutDuration()
+
+	cmd := osexec.Command("/bin/sh", "-c", hook.Command)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(timeout):
+		_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		<-done
+		return errors.Errorf("timed out after %v", timeout)
+	}
+}
+
+// runMachineHook runs a hook inside m. The command is run under timeout(1)
+// when the machine has it, so it doesn't outlive its timeout. The exec client
+// is killed if the hook still runs shortly after.
+func runMachineHook(m *Machine, hook *config.Hook, env []string) error {
+	timeout := hook.TimeoutDuration()
+	script := f(`if command -v timeout >/dev/null 2>&1; then exec timeout %d /bin/sh -c "$0"; fi; exec /bin/sh -c "$0"`,
+		int((timeout+time.Second-1)/time.Second))
+	cmd := m.cmder().Command("/bin/sh", "-c", script, hook.Command)
+	cmd.SetEnv(env...)
+	cmd.SetStdout(os.Stdout)
+	cmd.SetStderr(os.Stderr)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout+5*time.Second)
+	defer cancel()
+	err := exec.RunContext(ctx, cmd)
+	if ctx.Err() == context.DeadlineExceeded {
+		return errors.Errorf("timed out after %v", timeout)
+	}
+	return err
+}
+
+func runHooks(logger *log.Entry, point string, hooks []config.Hook, run func(*config.Hook) error) error {
+	for i := range hooks {
+		hook := &hooks[i]
+		logger.Infof("Running %s hook: %s", point, hook.Command)
+		if err := run(hook); err != nil {
+			return errors.Wrapf(err, "%s hook '%s'", point, hook.Command)
+		}
+	}
+	return nil
+}
+
+// runClusterHostHooks runs the cluster-wide host hooks of point.
+func (c *Cluster) runClusterHostHooks(point string) error {
+	env := c.hookEnv(nil)
+	return runHooks(log.NewEntry(log.StandardLogger()), point, hooks(c.spec.Cluster.Hooks, point), func(hook *config.Hook) error {
+		return runHostHook(hook, env)
+	})
+}
+
+// runMachineHostHooks runs the host hooks of point defined by m.
+func (c *Cluster) runMachineHostHooks(m *Machine, point string) error {
+	env := c.hookEnv(m)
+	return runHooks(m.logger(), point, hooks(m.spec.Hooks, point), func(hook *config.Hook) error {
+		return runHostHook(hook, env)
+	})
+}
+
+// runMachineHooks runs the hooks of point inside m: the cluster-wide ones
+// first, then the ones defined by m.
+func (c *Cluster) runMachineHooks(m *Machine, point string) error {
+	env := c.hookEnv(m)
+	all := append([]config.Hook{}, hooks(c.spec.Cluster.Hooks, point)...)
+	all = append(all, hooks(m.spec.Hooks, point)...)
+	return runHooks(m.logger(), point, all, func(hook *config.Hook) error {
+		return runMachineHook(m, hook, env)
+	})
+}
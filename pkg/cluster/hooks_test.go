@@ -0,0 +1,49 @@
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weaveworks/footloose/pkg/config"
+)
+
+func TestRunHostHook(t *testing.T) {
+	dir, err := ioutil.TempDir("", "footloose-hooks")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	c := newTestCluster(t, 1)
+	m := c.machineJobs()[0].machine
+	out := filepath.Join(dir, "out")
+
+	hook := &config.Hook{Command: `echo "$FOOTLOOSE_CLUSTER $FOOTLOOSE_MACHINE $FOOTLOOSE_CONTAINER" > ` + out}
+	assert.NoError(t, runHostHook(hook, c.hookEnv(m)))
+	data, err := ioutil.ReadFile(out)
+	assert.NoError(t, err)
+	assert.Equal(t, "cluster group0-node0 cluster-group0-node0\n", string(data))
+
+	assert.Error(t, runHostHook(&config.Hook{Command: "exit 3"}, nil))
+
+	err = runHostHook(&config.Hook{Command: "sleep 5", Timeout: "100ms"}, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out")
+}
+
+func TestRunHooksOrder(t *testing.T) {
+	hooks := []config.Hook{{Command: "a"}, {Command: "b"}, {Command: "c"}}
+
+	var ran []string
+	err := runHooks(newTestCluster(t, 1).machineJobs()[0].machine.logger(), hookHostPreCreate, hooks, func(hook *config.Hook) error {
+		ran = append(ran, hook.Command)
+		if hook.Command == "b" {
+			return os.ErrNotExist
+		}
+		return nil
+	})
+	assert.Error(t, err)
+	assert.Equal(t, []string{"a", "b"}, ran)
+}
@@ -0,0 +1,75 @@
+package cluster
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// imageChecks records the images that passed the image check, so they're only
+// checked once. It maps image IDs to the machine commands they were checked
+// with.
+type imageChecks map[string][]string
+
+func imageChecksPath() (string, error) {
+	dir, err := footlooseDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "image-checks.json"), nil
+}
+
+// loadImageChecks reads the recorded image checks. A missing record is empty.
+func loadImageChecks() (imageChecks, error) {
+	path, err := imageChecksPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return imageChecks{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	checks := imageChecks{}
+	if err := json.Unmarshal(data, &checks); err != nil {
+		return nil, errors.Wrapf(err, "image checks %s", path)
+	}
+	return checks, nil
+}
+
+func (checks imageChecks) save() error {
+	path, err := imageChecksPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(checks, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// passed returns whether the image id passed the check for machines running
+// cmd.
+func (checks imageChecks) passed(id, cmd string) bool {
+	for _, checked := range checks[id] {
+		if checked == cmd {
+			return true
+		}
+	}
+	return false
+}
+
+func (checks imageChecks) add(id, cmd string) {
+	if !checks.passed(id, cmd) {
+		checks[id] = append(checks[id], cmd)
+	}
+}
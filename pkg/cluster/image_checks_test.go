@@ -0,0 +1,26 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImageChecks(t *testing.T) {
+	defer withHome(t)()
+
+	checks, err := loadImageChecks()
+	assert.NoError(t, err)
+	assert.False(t, checks.passed("sha256:1234", ""))
+
+	checks.add("sha256:1234", "")
+	checks.add("sha256:1234", "")
+	assert.NoError(t, checks.save())
+
+	checks, err = loadImageChecks()
+	assert.NoError(t, err)
+	assert.Equal(t, imageChecks{"sha256:1234": {""}}, checks)
+	assert.True(t, checks.passed("sha256:1234", ""))
+	assert.False(t, checks.passed("sha256:1234", "sshd -D"))
+	assert.False(t, checks.passed("sha256:5678", ""))
+}
@@ -0,0 +1,97 @@
+package cluster
+
+import (
+	"path"
+	"strings"
+
+	"github.com/weaveworks/footloose/pkg/config"
+)
+
+// Machines are provisioned with POSIX shell scripts so images don't need bash.
+const machineShell = "/bin/sh"
+
+// defaultCmd is the command run in machine containers when the spec doesn't
+// specify one.
+const defaultCmd = "/sbin/init"
+
+func machineCommand(spec *config.Machine) string {
+	if spec.Cmd != "" {
+		return spec.Cmd
+	}
+	return defaultCmd
+}
+
+// runsSSHD returns whether cmd starts sshd directly, without an init system,
+// eg. "sshd -D" or "/usr/sbin/sshd -D -e".
+func runsSSHD(cmd string) bool {
+	args := strings.Fields(cmd)
+	return len(args) > 0 && path.Base(args[0]) == "sshd"
+}
+
+// sshdWrapper runs before sshd when the machine command starts it directly.
+// sshd exits when it has no host keys and refuses to run from a relative path,
+// so generate the keys and resolve sshd before handing over to it. $0 is sshd
+// and $@ its arguments.
+const sshdWrapper = `ssh-keygen -A >/dev/null 2>&1; mkdir -p /run/sshd /var/empty; sshd=$(command -v "$0"); exec "${sshd:-$0}" "$@"`
+
+// containerCommand returns the command line of the container running cmd.
+func containerCommand(cmd string) []string {
+	args := strings.Fields(cmd)
+	if !runsSSHD(cmd) {
+		return args
+	}
+	return append([]string{machineShell, "-c", sshdWrapper}, args...)
+}
+
+// machineCommandFromContainer is the reverse of containerCommand: it returns
+// the machine command of a container given its command line.
+func machineCommandFromContainer(args []string) string {
+	if len(args) > 3 && args[0] == machineShell && args[1] == "-c" && args[2] == sshdWrapper {
+		args = args[3:]
+	}
+	return strings.Join(args, " ")
+}
+
+// initScript prepares a freshly started container: it authorizes publicKey to
+// log in as root and makes sure sshd runs, whatever the init system.
+//
+//   - systemd starts sshd itself.
+//   - OpenRC, eg. Alpine with cmd /sbin/init, doesn't enable sshd by default.
+//   - Without an init system, the machine command is sshd (see runsSSHD) or
+//     something the image provides to start it.
+func initScript(publicKey []byte) string {
+	var s strings.Builder
+	s.WriteString(`set -e
+rm -f /run/nologin
+home=/root
+while IFS=: read -r user _ _ _ _ dir _; do
+  if [ "$user" = root ] && [ -n "$dir" ]; then home=$dir; fi
+done < /etc/passwd
+sshdir=$home/.ssh
+mkdir -p $sshdir; chmod 700 $sshdir
+cat <<'__EOF' >> $sshdir/authorized_keys
+`)
+	s.Write(publicKey)
+	if len(publicKey) > 0 && publicKey[len(publicKey)-1] != '\n' {
+		s.WriteString("\n")
+	}
+	s.WriteString(`__EOF
+chmod 600 $sshdir/authorized_keys
+
+init=$(cat /proc/1/comm 2>/dev/null || true)
+if [ "$init" = systemd ]; then
+  exit 0
+fi
+if command -v ssh-keygen >/dev/null 2>&1; then
+  ssh-keygen -A >/dev/null
+fi
+if command -v rc-service >/dev/null 2>&1 && { [ "$init" = init ] || [ "$init" = openrc-init ]; }; then
+  if [ ! -e /run/openrc/softlevel ]; then
+    mkdir -p /run/openrc; touch /run/openrc/softlevel
+  fi
+  rc-update add sshd default >/dev/null 2>&1 || true
+  rc-service sshd status >/dev/null 2>&1 || rc-service sshd start
+fi
+`)
+	return s.String()
+}
@@ -0,0 +1,33 @@
+package cluster
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunsSSHD(t *testing.T) {
+	assert.True(t, runsSSHD("sshd -D"))
+	assert.True(t, runsSSHD("/usr/sbin/sshd -D -e"))
+	assert.False(t, runsSSHD(""))
+	assert.False(t, runsSSHD("/sbin/init"))
+	assert.False(t, runsSSHD("/bin/sshd-wrapper"))
+}
+
+func TestContainerCommand(t *testing.T) {
+	for _, cmd := range []string{"/sbin/init", "/lib/systemd/systemd --log-level=debug", "sshd -D", "/usr/sbin/sshd -D -e"} {
+		assert.Equal(t, cmd, machineCommandFromContainer(containerCommand(cmd)), cmd)
+	}
+
+	assert.Equal(t, []string{"/sbin/init"}, containerCommand("/sbin/init"))
+	args := containerCommand("sshd -D")
+	assert.Equal(t, []string{"/bin/sh", "-c", sshdWrapper, "sshd", "-D"}, args)
+}
+
+func TestInitScript(t *testing.T) {
+	script := initScript([]byte("ssh-ed25519 AAAA"))
+	assert.True(t, strings.Contains(script, "ssh-ed25519 AAAA\n__EOF\n"))
+	assert.False(t, strings.Contains(script, "/root/.ssh"))
+	assert.True(t, strings.Contains(script, "rc-update add sshd default"))
+}
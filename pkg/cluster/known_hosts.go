@@ -0,0 +1,192 @@
+package cluster
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// knownHostsLock serializes known_hosts updates.
+var knownHostsLock sync.Mutex
+
+// hostKeysScript prints the machine sshd public host keys. Some distributions
+// only generate host keys the first time sshd starts, ssh-keygen -A makes sure
+// they exist by the time we read them.
+const hostKeysScript = `
+ssh-keygen -A >/dev/null 2>&1
+cat /etc/ssh/ssh_host_*_key.pub 2>/dev/null
+`
+
+// knownHostsPath is the path of the per-cluster known_hosts file holding the
+// machines host keys: next to the cluster private key, or in
+// ~/.footloose/clusters/<name> when there's no cluster-wide key.
+func (c *Cluster) knownHostsPath() (string, error) {
+	if c.spec.Cluster.PrivateKey == "" {
+		dir, err := homedir.Expand("~/.footloose/clusters")
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, c.spec.Cluster.Name, "known_hosts"), nil
+	}
+	path, err := homedir.Expand(c.spec.Cluster.PrivateKey)
+	if err != nil {
+		return "", err
+	}
+	return path + ".known_hosts", nil
+}
+
+// hostKeyAlias is the name under which the machine host keys are stored in the
+// known_hosts file. Using an alias rather than the host:port pair used to
+// connect to the machine means the entries stay valid when ports change.
+func hostKeyAlias(m *Machine) string {
+	return m.name
+}
+
+// machineHostKeys collects the sshd host keys of a machine.
+func machineHostKeys(m *Machine) ([]gossh.PublicKey, error) {
+	cmd := m.cmder().Command(machineShell, "-c", hostKeysScript)
+	var stdout bytes.Buffer
+	cmd.SetStdout(&stdout)
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrap(err, "host keys")
+	}
+
+	var keys []gossh.PublicKey
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		key, _, _, _, err := gossh.ParseAuthorizedKey([]byte(line))
+		if err != nil {
+			return nil, errors.Wrapf(err, "host keys: parse %q", line)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("host keys: no sshd host key found")
+	}
+	return keys, nil
+}
+
+// knownHostsLines returns the content of the known_hosts file, without the
+// entries for alias.
+func (c *Cluster) knownHostsLines(alias string) ([]string, error) {
+	path, err := c.knownHostsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] == alias {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+func (c *Cluster) writeKnownHosts(lines []string) error {
+	content := strings.Join(lines, "\n")
+	if len(lines) > 0 {
+		content += "\n"
+	}
+	path, err := c.knownHostsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(content), 0644)
+}
+
+// updateHostKeys collects the host keys of m and replaces its entries in the
+// known_hosts file.
+func (c *Cluster) updateHostKeys(m *Machine) error {
+	keys, err := machineHostKeys(m)
+	if err != nil {
+		return err
+	}
+
+	knownHostsLock.Lock()
+	defer knownHostsLock.Unlock()
+
+	alias := hostKeyAlias(m)
+	lines, err := c.knownHostsLines(alias)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		authorizedKey := strings.TrimSpace(string(gossh.MarshalAuthorizedKey(key)))
+		lines = append(lines, alias+" "+authorizedKey)
+	}
+	return c.writeKnownHosts(lines)
+}
+
+// removeHostKeys removes the entries of m from the known_hosts file.
+func (c *Cluster) removeHostKeys(m *Machine) error {
+	knownHostsLock.Lock()
+	defer knownHostsLock.Unlock()
+
+	path, err := c.knownHostsPath()
+	if err != nil || !fileExists(path) {
+		return err
+	}
+	lines, err := c.knownHostsLines(hostKeyAlias(m))
+	if err != nil {
+		return err
+	}
+	return c.writeKnownHosts(lines)
+}
+
+// hasHostKeys returns whether the known_hosts file has entries for m.
+func (c *Cluster) hasHostKeys(m *Machine) bool {
+	knownHostsLock.Lock()
+	defer knownHostsLock.Unlock()
+
+	path, err := c.knownHostsPath()
+	if err != nil {
+		return false
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == hostKeyAlias(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// ensureHostKeys makes sure the known_hosts file has entries for m, collecting
+// them if necessary. This covers machines created by older versions of
+// footloose or for which collecting the keys at creation time failed.
+func (c *Cluster) ensureHostKeys(m *Machine) error {
+	if c.hasHostKeys(m) {
+		return nil
+	}
+	m.logger().Infof("Collecting SSH host keys of %s ...", m.name)
+	return c.updateHostKeys(m)
+}
@@ -0,0 +1,55 @@
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weaveworks/footloose/pkg/config"
+)
+
+func TestKnownHostsLines(t *testing.T) {
+	dir, err := ioutil.TempDir("", "footloose-known-hosts")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cluster, err := New(config.Config{
+		Cluster: config.Cluster{Name: "cluster", PrivateKey: filepath.Join(dir, "cluster-key")},
+	})
+	assert.NoError(t, err)
+	path, err := cluster.knownHostsPath()
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "cluster-key.known_hosts"), path)
+
+	// No known_hosts file yet.
+	lines, err := cluster.knownHostsLines("cluster-node0")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(lines))
+
+	err = cluster.writeKnownHosts([]string{
+		"cluster-node0 ssh-ed25519 AAAA0",
+		"cluster-node1 ssh-ed25519 AAAA1",
+		"cluster-node0 ecdsa-sha2-nistp256 AAAA0",
+	})
+	assert.NoError(t, err)
+
+	lines, err = cluster.knownHostsLines("cluster-node0")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cluster-node1 ssh-ed25519 AAAA1"}, lines)
+}
+
+func TestKnownHostsPathWithoutPrivateKey(t *testing.T) {
+	cluster, err := New(config.Config{
+		Cluster: config.Cluster{Name: "cluster"},
+	})
+	assert.NoError(t, err)
+	path, err := cluster.knownHostsPath()
+	assert.NoError(t, err)
+	home, err := homedir.Dir()
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".footloose", "clusters", "cluster", "known_hosts"), path)
+}
@@ -0,0 +1,232 @@
+package cluster
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/weaveworks/footloose/pkg/docker"
+	"github.com/weaveworks/footloose/pkg/ignite"
+)
+
+// Log sources.
+const (
+	// LogsJournal is the systemd journal of the machine.
+	LogsJournal = "journal"
+	// LogsContainer is the output of the container, or the console of ignite
+	// VMs.
+	LogsContainer = "container"
+	// LogsAll is both the journal and the container output.
+	LogsAll = "all"
+)
+
+// LogsOptions are the options of Cluster.Logs.
+type LogsOptions struct {
+	// Source is where to read logs from: LogsJournal (the default),
+	// LogsContainer or LogsAll.
+	Source string
+	// Follow keeps streaming new logs until the streams end.
+	Follow bool
+	// Units restricts the journal to those systemd units.
+	Units []string
+	// Since only shows logs newer than a duration, eg. "10m", or a timestamp.
+	Since string
+}
+
+func (opts *LogsOptions) validate() error {
+	switch opts.Source {
+	case "", LogsJournal, LogsContainer, LogsAll:
+	default:
+		return errors.Errorf("unknown log source '%s'", opts.Source)
+	}
+	if len(opts.Units) > 0 && opts.Source == LogsContainer {
+		return errors.New("units only apply to the journal")
+	}
+	return nil
+}
+
+func (opts *LogsOptions) journal() bool {
+	return opts.Source == "" || opts.Source == LogsJournal || opts.Source == LogsAll
+}
+
+func (opts *LogsOptions) container() bool {
+	return opts.Source == LogsContainer || opts.Source == LogsAll
+}
+
+// journalctlArgs returns the journalctl command line for opts.
+func journalctlArgs(opts *LogsOptions) []string {
+	args := []string{"journalctl", "--no-pager", "--output", "short-iso"}
+	if opts.Follow {
+		args = append(args, "--follow")
+	}
+	for _, unit := range opts.Units {
+		args = append(args, "--unit", unit)
+	}
+	if opts.Since != "" {
+		since := opts.Since
+		// journalctl wants relative times as "-600s".
+		if d, err := time.ParseDuration(since); err == nil {
+			since = "-" + strconv.Itoa(int(d.Seconds())) + "s"
+		}
+		args = append(args, "--since", since)
+	}
+	return args
+}
+
+// prefixWriter writes complete lines to w, prefixed with prefix. Writers
+// sharing the same mutex don't interleave their lines.
+type prefixWriter struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (p *prefixWriter) Write(data []byte) (int, error) {
+	p.buf = append(p.buf, data...)
+	for {
+		i := bytes.IndexByte(p.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if err := p.writeLine(p.buf[:i]); err != nil {
+			return 0, err
+		}
+		p.buf = p.buf[i+1:]
+	}
+	return len(data), nil
+}
+
+// Flush writes what's left of the last line, if it isn't terminated.
+func (p *prefixWriter) Flush() error {
+	if len(p.buf) == 0 {
+		return nil
+	}
+	err := p.writeLine(p.buf)
+	p.buf = nil
+	return err
+}
+
+func (p *prefixWriter) writeLine(line []byte) error {
+	// Commands run with a tty end their lines with \r\n.
+	line = bytes.TrimRight(line, "\r")
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	_, err := io.WriteString(p.w, p.prefix+string(line)+"\n")
+	return err
+}
+
+// selectMachines returns the machines with the given hostnames, or all the
+// machines of the cluster.
+func (c *Cluster) selectMachines(hostnames []string) ([]*Machine, error) {
+	if len(hostnames) == 0 {
+		return c.gatherMachinesByCluster(), nil
+	}
+	var machines []*Machine
+	for _, hostname := range hostnames {
+		m, err := c.machineFromHostname(hostname)
+		if err != nil {
+			return nil, err
+		}
+		machines = append(machines, m)
+	}
+	return machines, nil
+}
+
+// machineLogs streams the logs of m from source to w.
+func machineLogs(m *Machine, source string, opts *LogsOptions, w io.Writer) error {
+	switch source {
+	case LogsJournal:
+		args := journalctlArgs(opts)
+		cmd := m.cmder().Command(args[0], args[1:]...)
+		cmd.SetStdout(w)
+		cmd.SetStderr(w)
+		return errors.Wrap(cmd.Run(), "journal")
+	case LogsContainer:
+		var err error
+		if m.IsIgnite() {
+			err = ignite.Logs(m.name, w, w)
+		} else {
+			err = docker.Logs(m.name, opts.Follow, opts.Since, w, w)
+		}
+		return errors.Wrap(err, "container logs")
+	}
+	return nil
+}
+
+// Logs streams the logs of the machines with the given hostnames, or of all
+// machines, to w. Lines are prefixed with the machine hostname. When several
+// streams are read, they are read concurrently and their lines interleaved.
+//
+// Ignite doesn't support following the VM console or filtering it by time:
+// the whole console output is shown once.
+func (c *Cluster) Logs(hostnames []string, opts LogsOptions, w io.Writer) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+	machines, err := c.selectMachines(hostnames)
+	if err != nil {
+		return err
+	}
+
+	var sources []string
+	if opts.container() {
+		sources = append(sources, LogsContainer)
+	}
+	if opts.journal() {
+		sources = append(sources, LogsJournal)
+	}
+
+	width := 0
+	for _, m := range machines {
+		if len(m.hostname) > width {
+			width = len(m.hostname)
+		}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	errs := make([]error, len(machines)*len(sources))
+	for i, m := range machines {
+		if !m.IsCreated() {
+			log.Warnf("machine %s hasn't been created", m.hostname)
+			continue
+		}
+		for j, source := range sources {
+			wg.Add(1)
+			go func(m *Machine, source string, n int) {
+				defer wg.Done()
+				pw := &prefixWriter{
+					mu:     &mu,
+					w:      w,
+					prefix: m.hostname + strings.Repeat(" ", width-len(m.hostname)) + " | ",
+				}
+				errs[n] = machineLogs(m, source, &opts, pw)
+				if err := pw.Flush(); err != nil && errs[n] == nil {
+					errs[n] = err
+				}
+			}(m, source, i*len(sources)+j)
+		}
+	}
+	wg.Wait()
+
+	var machineErrs MachineErrors
+	for n, err := range errs {
+		if err != nil {
+			machineErrs = append(machineErrs, &MachineError{
+				Machine: machines[n/len(sources)].name,
+				Err:     err,
+			})
+		}
+	}
+	if len(machineErrs) > 0 {
+		return machineErrs
+	}
+	return nil
+}
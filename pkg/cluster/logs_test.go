@@ -0,0 +1,55 @@
+package cluster
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournalctlArgs(t *testing.T) {
+	tests := []struct {
+		opts     LogsOptions
+		expected []string
+	}{
+		{LogsOptions{}, []string{"journalctl", "--no-pager", "--output", "short-iso"}},
+		{
+			LogsOptions{Follow: true, Units: []string{"sshd", "docker"}},
+			[]string{"journalctl", "--no-pager", "--output", "short-iso", "--follow", "--unit", "sshd", "--unit", "docker"},
+		},
+		{
+			LogsOptions{Since: "10m"},
+			[]string{"journalctl", "--no-pager", "--output", "short-iso", "--since", "-600s"},
+		},
+		{
+			LogsOptions{Since: "2019-06-01 10:00:00"},
+			[]string{"journalctl", "--no-pager", "--output", "short-iso", "--since", "2019-06-01 10:00:00"},
+		},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, journalctlArgs(&test.opts))
+	}
+}
+
+func TestLogsOptionsValidate(t *testing.T) {
+	assert.NoError(t, (&LogsOptions{}).validate())
+	assert.NoError(t, (&LogsOptions{Source: LogsAll, Units: []string{"sshd"}}).validate())
+	assert.Error(t, (&LogsOptions{Source: "foo"}).validate())
+	assert.Error(t, (&LogsOptions{Source: LogsContainer, Units: []string{"sshd"}}).validate())
+}
+
+func TestPrefixWriter(t *testing.T) {
+	var mu sync.Mutex
+	var out bytes.Buffer
+	node0 := &prefixWriter{mu: &mu, w: &out, prefix: "node0 | "}
+	node10 := &prefixWriter{mu: &mu, w: &out, prefix: "node10 | "}
+
+	node0.Write([]byte("first\r\nsec"))
+	node10.Write([]byte("other\n"))
+	node0.Write([]byte("ond\nlast"))
+	assert.NoError(t, node0.Flush())
+	assert.NoError(t, node10.Flush())
+
+	assert.Equal(t, "node0 | first\nnode10 | other\nnode0 | second\nnode0 | last\n", out.String())
+}
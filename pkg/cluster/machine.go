@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 
 	"github.com/docker/docker/api/types/network"
@@ -25,6 +26,8 @@ type Machine struct {
 	hostname string
 	// container ip.
 	ip string
+	// command the container runs.
+	command string
 
 	runtimeNetworks []*RuntimeNetwork
 	// Fields that are cached from the docker daemon.
@@ -52,6 +55,36 @@ func (m *Machine) Hostname() string {
 	return m.hostname
 }
 
+// logger returns a logger tagging entries with the machine hostname.
+func (m *Machine) logger() *log.Entry {
+	return log.WithField("machine", m.hostname)
+}
+
+// cmder returns an exec.Cmder running commands inside the machine.
+func (m *Machine) cmder() exec.Cmder {
+	if m.IsIgnite() {
+		return ignite.VMCmder(m.name)
+	}
+	return docker.ContainerCmder(m.name)
+}
+
+// CopyTo copies the file or directory at hostPath into the machine at path.
+func (m *Machine) CopyTo(hostPath, path string) error {
+	if m.IsIgnite() {
+		return ignite.CopyTo(hostPath, m.name, path)
+	}
+	return docker.CopyTo(hostPath, m.name, path)
+}
+
+// CopyFrom copies the file or directory at path in the machine to the host at
+// hostPath.
+func (m *Machine) CopyFrom(path, hostPath string) error {
+	if m.IsIgnite() {
+		return ignite.CopyFrom(m.name, path, hostPath)
+	}
+	return docker.CopyFrom(m.name, path, hostPath)
+}
+
 // IsCreated returns if a machine is has been created. A created machine could
 // either be running or stopped.
 func (m *Machine) IsCreated() bool {
@@ -202,6 +235,9 @@ func (m *Machine) Status() *MachineStatus {
 	s.Container = m.ContainerName()
 	s.Image = m.spec.Image
 	s.Command = m.spec.Cmd
+	if m.command != "" {
+		s.Command = m.command
+	}
 	s.Spec = m.spec
 	s.Hostname = m.Hostname()
 	s.IP = m.ip
@@ -214,6 +250,9 @@ func (m *Machine) Status() *MachineStatus {
 		}
 	}
 	s.State = state
+	if state == Running {
+		s.Ready = m.IsReady()
+	}
 
 	if m.IsIgnite() {
 		_ = m.igniteStatus(&s)
@@ -225,19 +264,20 @@ func (m *Machine) Status() *MachineStatus {
 }
 
 // Only check for Ignite prerequisites once
-var igniteChecked bool
+var igniteCheck sync.Once
 
 // IsIgnite returns if the backend is Ignite
 func (m *Machine) IsIgnite() (b bool) {
 	b = m.spec.Backend == ignite.BackendName
 
-	if !igniteChecked && b {
-		if syscall.Getuid() != 0 {
-			log.Fatalf("Footloose needs to run as root to use the %q backend", ignite.BackendName)
-		}
+	if b {
+		igniteCheck.Do(func() {
+			if syscall.Getuid() != 0 {
+				log.Fatalf("Footloose needs to run as root to use the %q backend", ignite.BackendName)
+			}
 
-		ignite.CheckVersion()
-		igniteChecked = true
+			ignite.CheckVersion()
+		})
 	}
 
 	return
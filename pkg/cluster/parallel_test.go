@@ -0,0 +1,93 @@
+package cluster
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weaveworks/footloose/pkg/config"
+)
+
+func newTestCluster(t *testing.T, counts ...int) *Cluster {
+	conf := config.Config{
+		Cluster: config.Cluster{Name: "cluster"},
+	}
+	for i, count := range counts {
+		conf.Machines = append(conf.Machines, config.MachineReplicas{
+			Count: count,
+			Spec: config.Machine{
+				Name: f("group%d-node%%d", i),
+			},
+		})
+	}
+	c, err := New(conf)
+	assert.NoError(t, err)
+	return c
+}
+
+func TestForEachMachineIndexes(t *testing.T) {
+	c := newTestCluster(t, 2, 3).SetParallel(4)
+
+	var lock sync.Mutex
+	indexes := make(map[string]int)
+	err := c.forEachMachine(func(m *Machine, i int) error {
+		lock.Lock()
+		defer lock.Unlock()
+		indexes[m.name] = i
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{
+		"cluster-group0-node0": 0,
+		"cluster-group0-node1": 1,
+		"cluster-group1-node0": 2,
+		"cluster-group1-node1": 3,
+		"cluster-group1-node2": 4,
+	}, indexes)
+}
+
+func TestForEachMachineParallelism(t *testing.T) {
+	c := newTestCluster(t, 10).SetParallel(3)
+
+	var running, max int32
+	err := c.forEachMachine(func(m *Machine, i int) error {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			cur := atomic.LoadInt32(&max)
+			if n <= cur || atomic.CompareAndSwapInt32(&max, cur, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, max <= 3)
+	assert.True(t, max > 1)
+}
+
+func TestForEachMachineErrors(t *testing.T) {
+	c := newTestCluster(t, 4).SetParallel(2)
+
+	var calls int32
+	err := c.forEachMachine(func(m *Machine, i int) error {
+		atomic.AddInt32(&calls, 1)
+		if i%2 == 1 {
+			return errors.New("failed")
+		}
+		return nil
+	})
+	// All machines are operated on, even when some fail.
+	assert.Equal(t, int32(4), calls)
+	errs, ok := err.(MachineErrors)
+	assert.True(t, ok)
+	assert.Equal(t, 2, len(errs))
+	assert.Equal(t, "cluster-group0-node1", errs[0].Machine)
+	assert.Equal(t, "cluster-group0-node3", errs[1].Machine)
+	assert.Equal(t, "2 machines failed:\n  cluster-group0-node1: failed\n  cluster-group0-node3: failed", err.Error())
+}
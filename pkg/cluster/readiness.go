@@ -0,0 +1,184 @@
+package cluster
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/weaveworks/footloose/pkg/config"
+	"github.com/weaveworks/footloose/pkg/exec"
+)
+
+// readinessPollInterval is the time between two attempts at a readiness check.
+const readinessPollInterval = 500 * time.Millisecond
+
+// readinessChecks returns the checks deciding if m is ready.
+func (m *Machine) readinessChecks() []config.ReadinessCheck {
+	if len(m.spec.Readiness) > 0 {
+		return m.spec.Readiness
+	}
+	if _, err := mappingFromPort(m.spec, 22); err == nil {
+		return []config.ReadinessCheck{{Type: config.ReadinessSSH}}
+	}
+	return nil
+}
+
+// hostAddress returns the host address a machine port is reachable at.
+func (m *Machine) hostAddress(containerPort int) (string, error) {
+	mapping, err := mappingFromPort(m.spec, containerPort)
+	if err != nil {
+		return "", err
+	}
+	hostPort, err := m.HostPort(containerPort)
+	if err != nil {
+		return "", err
+	}
+	remote := "localhost"
+	if mapping.Address != "" {
+		remote = mapping.Address
+	}
+	return net.JoinHostPort(remote, f("%d", hostPort)), nil
+}
+
+func dial(address string) (net.Conn, error) {
+	conn, err := net.DialTimeout("tcp", address, 2*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return conn, conn.SetDeadline(time.Now().Add(2 * time.Second))
+}
+
+// checkSSH checks sshd sends its identification string.
+func (m *Machine) checkSSH() error {
+	address, err := m.hostAddress(22)
+	if err != nil {
+		return err
+	}
+	conn, err := dial(address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	banner, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return errors.Wrap(err, "read SSH identification")
+	}
+	if !strings.HasPrefix(banner, "SSH-") {
+		return errors.Errorf("unexpected SSH identification %q", banner)
+	}
+	return nil
+}
+
+func (m *Machine) checkTCP(port int) error {
+	address, err := m.hostAddress(port)
+	if err != nil {
+		return err
+	}
+	conn, err := dial(address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// checkSystemd checks the machine has finished booting. We poll
+// "systemctl is-system-running" rather than using its --wait option as it
+// requires systemd 240 and most distributions we support have an older systemd.
+func (m *Machine) checkSystemd() error {
+	// is-system-running exits with a non-zero status when the system is
+	// degraded. Containers often have a failing unit or two, consider it ready.
+	lines, _ := exec.CombinedOutputLines(m.cmder().Command("systemctl", "is-system-running"))
+	state := ""
+	if len(lines) > 0 {
+		state = strings.TrimSpace(lines[len(lines)-1])
+	}
+	if state != "running" && state != "degraded" {
+		return errors.Errorf("system is %q", state)
+	}
+	return nil
+}
+
+func (m *Machine) checkCommand(command string) error {
+	return m.cmder().Command(machineShell, "-c", command).Run()
+}
+
+// check runs a single readiness check.
+func (m *Machine) check(check *config.ReadinessCheck) error {
+	switch check.Type {
+	case config.ReadinessSSH:
+		return m.checkSSH()
+	case config.ReadinessSystemd:
+		return m.checkSystemd()
+	case config.ReadinessTCP:
+		return m.checkTCP(int(check.Port))
+	case config.ReadinessCommand:
+		return m.checkCommand(check.Command)
+	}
+	return errors.Errorf("unknown readiness check type '%s'", check.Type)
+}
+
+// IsReady returns whether the machine is running and passes all its readiness
+// checks.
+func (m *Machine) IsReady() bool {
+	if !m.IsCreated() || !m.IsStarted() {
+		return false
+	}
+	checks := m.readinessChecks()
+	for i := range checks {
+		if err := m.check(&checks[i]); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// WaitReady waits until the machine passes all its readiness checks, or the
+// timeout expires.
+func (m *Machine) WaitReady(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	if !m.IsCreated() {
+		return errors.Errorf("machine %s hasn't been created", m.name)
+	}
+	if !m.IsStarted() {
+		return errors.Errorf("machine %s isn't started", m.name)
+	}
+
+	checks := m.readinessChecks()
+	for i := range checks {
+		check := &checks[i]
+		m.logger().Infof("Waiting for machine %s: %s check ...", m.name, check.Type)
+		for {
+			err := m.check(check)
+			if err == nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				return errors.Wrapf(err, "machine %s: %s check: timed out after %v", m.name, check.Type, timeout)
+			}
+			time.Sleep(readinessPollInterval)
+		}
+	}
+	m.logger().Infof("Machine %s is ready", m.name)
+	return nil
+}
+
+// Wait waits for the given machines, or all the cluster machines if none are
+// given, to be ready.
+func (c *Cluster) Wait(machineNames []string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	wait := func(m *Machine, _ int) error {
+		remaining := time.Until(deadline)
+		if remaining < 0 {
+			remaining = 0
+		}
+		return m.WaitReady(remaining)
+	}
+	if len(machineNames) < 1 {
+		return c.forEachMachine(wait)
+	}
+	return c.forSpecificMachines(wait, machineNames)
+}
@@ -0,0 +1,64 @@
+package cluster
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weaveworks/footloose/pkg/config"
+)
+
+func TestReadinessChecksDefault(t *testing.T) {
+	m := &Machine{spec: &config.Machine{
+		PortMappings: []config.PortMapping{{ContainerPort: 22}},
+	}}
+	assert.Equal(t, []config.ReadinessCheck{{Type: config.ReadinessSSH}}, m.readinessChecks())
+
+	m = &Machine{spec: &config.Machine{}}
+	assert.Equal(t, 0, len(m.readinessChecks()))
+
+	checks := []config.ReadinessCheck{{Type: config.ReadinessTCP, Port: 80}}
+	m = &Machine{spec: &config.Machine{Readiness: checks}}
+	assert.Equal(t, checks, m.readinessChecks())
+}
+
+// serveBanner starts a TCP server writing banner to each connection.
+func serveBanner(t *testing.T, banner string) net.Listener {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte(banner))
+			conn.Close()
+		}
+	}()
+	return l
+}
+
+func machineWithSSHPort(l net.Listener) *Machine {
+	return &Machine{
+		spec: &config.Machine{
+			PortMappings: []config.PortMapping{{ContainerPort: 22, Address: "127.0.0.1"}},
+		},
+		ports: map[int]int{22: l.Addr().(*net.TCPAddr).Port},
+	}
+}
+
+func TestCheckSSH(t *testing.T) {
+	l := serveBanner(t, "SSH-2.0-OpenSSH_7.4\r\n")
+	defer l.Close()
+	m := machineWithSSHPort(l)
+	assert.NoError(t, m.checkSSH())
+	assert.NoError(t, m.checkTCP(22))
+
+	l = serveBanner(t, "HTTP/1.1 400 Bad Request\r\n")
+	defer l.Close()
+	m = machineWithSSHPort(l)
+	assert.Error(t, m.checkSSH())
+	assert.NoError(t, m.checkTCP(22))
+}
@@ -0,0 +1,146 @@
+package cluster
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/weaveworks/footloose/pkg/config"
+	"github.com/weaveworks/footloose/pkg/docker"
+)
+
+// clusterExpiry is what's needed to know when a cluster expires.
+type clusterExpiry struct {
+	name string
+	// created is the creation time of the oldest machine of the cluster.
+	created time.Time
+	ttl     time.Duration
+}
+
+// deadline returns when the cluster expires: its recorded deadline when it
+// has been extended, the creation of its oldest machine plus its TTL
+// otherwise.
+func (e *clusterExpiry) deadline() time.Time {
+	if state, _ := LoadState(e.name); state != nil && state.Deadline != nil {
+		return *state.Deadline
+	}
+	return e.created.Add(e.ttl)
+}
+
+// clusterExpiries returns the expiry of the clusters with a TTL, sorted by
+// name. Containers created before footloose labelled them with their
+// creation time and TTL are ignored.
+func clusterExpiries(containers []hostContainer) []*clusterExpiry {
+	clusters := make(map[string]*clusterExpiry)
+	var names []string
+	for _, container := range containers {
+		created, err := time.Parse(time.RFC3339, container.created)
+		if err != nil {
+			continue
+		}
+		ttl := config.Cluster{TTL: container.ttl}.TTLDuration()
+		if ttl <= 0 {
+			continue
+		}
+		expiry, ok := clusters[container.cluster]
+		if !ok {
+			expiry = &clusterExpiry{name: container.cluster, created: created, ttl: ttl}
+			clusters[container.cluster] = expiry
+			names = append(names, container.cluster)
+		}
+		if created.Before(expiry.created) {
+			expiry.created = created
+		}
+	}
+
+	sort.Strings(names)
+	var expiries []*clusterExpiry
+	for _, name := range names {
+		expiries = append(expiries, clusters[name])
+	}
+	return expiries
+}
+
+// Reap deletes the clusters which TTL has expired. It returns the names of the
+// deleted clusters. Only docker machines are taken into account.
+func Reap(parallel int) ([]string, error) {
+	if err := docker.IsRunning(); err != nil {
+		return nil, err
+	}
+	containers, err := hostContainers("label=" + ttlLabel)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var deleted []string
+	for _, expiry := range clusterExpiries(containers) {
+		deadline := expiry.deadline()
+		if now.Before(deadline) {
+			continue
+		}
+		log.Infof("Cluster %s expired at %s, deleting it...", expiry.name, deadline.Format(time.RFC3339))
+		if err := DeleteByName(expiry.name, parallel); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, expiry.name)
+	}
+	return deleted, nil
+}
+
+// Extend pushes back the deadline of the cluster name by d, from now if the
+// cluster has already expired. The new deadline is recorded in the cluster
+// state and returned. The cluster must have a TTL.
+func Extend(name string, d time.Duration) (time.Time, error) {
+	if d <= 0 {
+		return time.Time{}, errors.Errorf("invalid extension %v", d)
+	}
+	if err := docker.IsRunning(); err != nil {
+		return time.Time{}, err
+	}
+	containers, err := hostContainers("label=" + clusterLabel + "=" + name)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(containers) == 0 {
+		return time.Time{}, errors.Errorf("no machine found for cluster %s", name)
+	}
+	expiries := clusterExpiries(containers)
+	if len(expiries) == 0 {
+		return time.Time{}, errors.Errorf("cluster %s doesn't have a ttl", name)
+	}
+
+	deadline := expiries[0].deadline()
+	if now := time.Now(); deadline.Before(now) {
+		deadline = now
+	}
+	deadline = deadline.Add(d).UTC().Truncate(time.Second)
+
+	c := &Cluster{
+		spec: config.Config{
+			Cluster: config.Cluster{Name: name},
+		},
+	}
+	if err := c.updateState(func(s *State) {
+		s.Deadline = &deadline
+	}); err != nil {
+		return time.Time{}, err
+	}
+	return deadline, nil
+}
+
+// clearDeadline forgets the extended deadline recorded for the cluster. A new
+// cluster doesn't inherit the deadline of an earlier cluster with the same
+// name.
+func (c *Cluster) clearDeadline() error {
+	stateLock.Lock()
+	defer stateLock.Unlock()
+
+	state, err := LoadState(c.spec.Cluster.Name)
+	if err != nil || state == nil || state.Deadline == nil {
+		return err
+	}
+	state.Deadline = nil
+	return state.save()
+}
@@ -0,0 +1,60 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weaveworks/footloose/pkg/config"
+)
+
+func TestClusterExpiries(t *testing.T) {
+	expiries := clusterExpiries([]hostContainer{
+		{name: "b-node0", cluster: "b", created: "2019-06-01T12:00:00Z", ttl: "1h"},
+		{name: "a-node1", cluster: "a", created: "2019-06-01T10:30:00Z", ttl: "2h"},
+		{name: "a-node0", cluster: "a", created: "2019-06-01T10:00:00Z", ttl: "2h"},
+		// No TTL.
+		{name: "c-node0", cluster: "c", created: "2019-06-01T10:00:00Z"},
+		// Created before footloose labelled containers with their creation.
+		{name: "d-node0", cluster: "d", ttl: "1h"},
+	})
+	assert.Equal(t, []*clusterExpiry{
+		{name: "a", created: time.Date(2019, 6, 1, 10, 0, 0, 0, time.UTC), ttl: 2 * time.Hour},
+		{name: "b", created: time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC), ttl: time.Hour},
+	}, expiries)
+}
+
+func TestClusterExpiryDeadline(t *testing.T) {
+	defer withHome(t)()
+
+	expiry := &clusterExpiry{
+		name:    "cluster",
+		created: time.Date(2019, 6, 1, 10, 0, 0, 0, time.UTC),
+		ttl:     2 * time.Hour,
+	}
+	assert.Equal(t, time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC), expiry.deadline())
+
+	// Extended deadlines are recorded in the cluster state.
+	extended := time.Date(2019, 6, 1, 15, 0, 0, 0, time.UTC)
+	c := newTestCluster(t, 1)
+	assert.NoError(t, c.updateState(func(s *State) {
+		s.Deadline = &extended
+	}))
+	assert.True(t, extended.Equal(expiry.deadline()))
+
+	// New clusters start from their TTL again.
+	assert.NoError(t, c.clearDeadline())
+	assert.Equal(t, time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC), expiry.deadline())
+}
+
+func TestClusterTTLValidation(t *testing.T) {
+	for _, ttl := range []string{"", "8h", "30m"} {
+		conf := config.Config{Cluster: config.Cluster{Name: "cluster", TTL: ttl}}
+		assert.NoError(t, conf.Validate(), ttl)
+	}
+	for _, ttl := range []string{"8", "-1h", "0s", "tomorrow"} {
+		conf := config.Config{Cluster: config.Cluster{Name: "cluster", TTL: ttl}}
+		assert.Error(t, conf.Validate(), ttl)
+	}
+}
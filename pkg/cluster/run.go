@@ -1,9 +1,6 @@
 package cluster
 
 import (
-	"bytes"
-	"fmt"
-
 	log "github.com/sirupsen/logrus"
 
 	"github.com/weaveworks/footloose/pkg/docker"
@@ -25,26 +22,31 @@ func run(name string, args ...string) error {
 
 // Run a command in a container. It will output the combined stdout/error on failure.
 func containerRun(nameOrID string, name string, args ...string) error {
-	exe := docker.ContainerCmder(nameOrID)
+	return cmderRun(docker.ContainerCmder(nameOrID), nameOrID, name, args...)
+}
+
+// Run a command in a machine, whatever its backend. It will output the
+// combined stdout/error on failure.
+func machineRun(m *Machine, name string, args ...string) error {
+	return cmderRun(m.cmder(), m.name, name, args...)
+}
+
+func machineRunShell(m *Machine, script string) error {
+	return machineRun(m, machineShell, "-c", script)
+}
+
+func cmderRun(exe exec.Cmder, machine string, name string, args ...string) error {
 	cmd := exe.Command(name, args...)
 	output, err := exec.CombinedOutputLines(cmd)
 	if err != nil {
 		// log error output if there was any
 		for _, line := range output {
-			log.WithField("machine", nameOrID).Error(line)
+			log.WithField("machine", machine).Error(line)
 		}
 	}
 	return err
 }
 
 func containerRunShell(nameOrID string, script string) error {
-	return containerRun(nameOrID, "/bin/bash", "-c", script)
-}
-
-func copy(nameOrID string, content []byte, path string) error {
-	buf := bytes.Buffer{}
-	buf.WriteString(fmt.Sprintf("cat <<__EOF | tee -a %s\n", path))
-	buf.Write(content)
-	buf.WriteString("__EOF")
-	return containerRunShell(nameOrID, buf.String())
+	return containerRun(nameOrID, machineShell, "-c", script)
 }
@@ -0,0 +1,203 @@
+package cluster
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/weaveworks/footloose/pkg/docker"
+)
+
+// Snapshot records the images committed from the machines of a cluster.
+type Snapshot struct {
+	// Name is the snapshot name.
+	Name string `json:"name"`
+	// Cluster is the name of the cluster the snapshot was taken from.
+	Cluster string `json:"cluster"`
+	// Created is the time the snapshot was taken.
+	Created time.Time `json:"created"`
+	// PublicKey is the cluster public key at the time the snapshot was taken.
+	PublicKey string `json:"publicKey,omitempty"`
+	// Machines lists the machine images.
+	Machines []SnapshotMachine `json:"machines"`
+}
+
+// SnapshotMachine is the image committed from a machine.
+type SnapshotMachine struct {
+	// Hostname is the machine hostname.
+	Hostname string `json:"hostname"`
+	// Image is the image holding the machine filesystem.
+	Image string `json:"image"`
+}
+
+// image returns the image committed from the machine with the given hostname.
+func (s *Snapshot) image(hostname string) (string, bool) {
+	for _, m := range s.Machines {
+		if m.Hostname == hostname {
+			return m.Image, true
+		}
+	}
+	return "", false
+}
+
+// footlooseDir is the directory footloose stores its state in.
+func footlooseDir() (string, error) {
+	return homedir.Expand("~/.footloose")
+}
+
+var snapshotNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127}$`)
+
+func validateSnapshotName(name string) error {
+	if !snapshotNameRegexp.MatchString(name) {
+		return errors.Errorf("invalid snapshot name '%s': only letters, digits, '_', '.' and '-' are allowed", name)
+	}
+	return nil
+}
+
+// snapshotPath returns the path of the manifest of a snapshot.
+func snapshotPath(cluster, name string) (string, error) {
+	dir, err := footlooseDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "snapshots", cluster, name+".json"), nil
+}
+
+// snapshotImage returns the name of the image committed from a machine.
+func snapshotImage(cluster, hostname, name string) string {
+	return strings.ToLower(f("footloose-snapshot/%s-%s", cluster, hostname)) + ":" + name
+}
+
+// LoadSnapshot reads the manifest of a snapshot of a cluster.
+func LoadSnapshot(cluster, name string) (*Snapshot, error) {
+	if err := validateSnapshotName(name); err != nil {
+		return nil, err
+	}
+	path, err := snapshotPath(cluster, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, errors.Errorf("cluster %s has no snapshot named '%s'", cluster, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, errors.Wrapf(err, "snapshot %s", path)
+	}
+	return s, nil
+}
+
+func (s *Snapshot) save() error {
+	path, err := snapshotPath(s.Cluster, s.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// Snapshot commits the filesystem of each machine of the cluster to an image
+// and records them in a snapshot manifest.
+func (c *Cluster) Snapshot(name string) (*Snapshot, error) {
+	if err := validateSnapshotName(name); err != nil {
+		return nil, err
+	}
+	if err := docker.IsRunning(); err != nil {
+		return nil, err
+	}
+
+	jobs := c.machineJobs()
+	snapshot := &Snapshot{
+		Name:     name,
+		Cluster:  c.spec.Cluster.Name,
+		Created:  time.Now().UTC(),
+		Machines: make([]SnapshotMachine, len(jobs)),
+	}
+	if key, err := c.clusterPublicKey(); err == nil {
+		snapshot.PublicKey = strings.TrimSpace(string(key))
+	}
+
+	// Jobs are given their position in the snapshot as index.
+	for i := range jobs {
+		jobs[i].index = i
+	}
+	err := c.runJobs(jobs, func(m *Machine, i int) error {
+		if !m.IsCreated() {
+			return errors.Errorf("machine %s hasn't been created", m.name)
+		}
+		if m.IsIgnite() {
+			return errors.Errorf("machine %s: snapshots aren't supported by the %s backend", m.name, m.spec.Backend)
+		}
+		image := snapshotImage(c.spec.Cluster.Name, m.hostname, name)
+		m.logger().Infof("Committing machine %s to %s ...", m.name, image)
+		if err := docker.Commit(m.name, image); err != nil {
+			return err
+		}
+		snapshot.Machines[i] = SnapshotMachine{
+			Hostname: m.hostname,
+			Image:    image,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := snapshot.save(); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// SetSnapshot makes the cluster machines be created from the images of a
+// snapshot instead of the images of their spec.
+func (c *Cluster) SetSnapshot(snapshot *Snapshot) error {
+	if snapshot.Cluster != c.spec.Cluster.Name {
+		return errors.Errorf("snapshot %s was taken from cluster %s, not %s", snapshot.Name, snapshot.Cluster, c.spec.Cluster.Name)
+	}
+	for _, job := range c.machineJobs() {
+		m := job.machine
+		if m.IsIgnite() {
+			return errors.Errorf("machine %s: snapshots aren't supported by the %s backend", m.name, m.spec.Backend)
+		}
+		if _, ok := snapshot.image(m.hostname); !ok {
+			return errors.Errorf("snapshot %s has no image for machine %s", snapshot.Name, m.hostname)
+		}
+	}
+	if snapshot.PublicKey != "" {
+		key, err := c.clusterPublicKey()
+		if err == nil && strings.TrimSpace(string(key)) != snapshot.PublicKey {
+			log.Warnf("Snapshot %s was taken with a different SSH key than %s", snapshot.Name, c.spec.Cluster.PrivateKey)
+		}
+	}
+	c.snapshot = snapshot
+	return nil
+}
+
+// machineImage returns the image to create m from.
+func (c *Cluster) machineImage(m *Machine) string {
+	if c.snapshot != nil {
+		if image, ok := c.snapshot.image(m.hostname); ok {
+			return image
+		}
+	}
+	return m.spec.Image
+}
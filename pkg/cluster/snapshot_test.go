@@ -0,0 +1,81 @@
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/stretchr/testify/assert"
+)
+
+// withHome points the home directory to a temporary directory for the
+// duration of a test.
+func withHome(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "footloose-home")
+	assert.NoError(t, err)
+	home := os.Getenv("HOME")
+	os.Setenv("HOME", dir)
+	homedir.DisableCache = true
+	return func() {
+		os.Setenv("HOME", home)
+		homedir.DisableCache = false
+		os.RemoveAll(dir)
+	}
+}
+
+func TestValidateSnapshotName(t *testing.T) {
+	for _, name := range []string{"base", "v1.2", "20191019-150405", "_x"} {
+		assert.NoError(t, validateSnapshotName(name), name)
+	}
+	for _, name := range []string{"", "-x", "a/b", "a:b", "with space"} {
+		assert.Error(t, validateSnapshotName(name), name)
+	}
+}
+
+func TestSnapshotImage(t *testing.T) {
+	assert.Equal(t, "footloose-snapshot/mycluster-node0:base", snapshotImage("MyCluster", "node0", "base"))
+}
+
+func TestLoadSnapshot(t *testing.T) {
+	defer withHome(t)()
+
+	_, err := LoadSnapshot("cluster", "base")
+	assert.Error(t, err)
+
+	s := &Snapshot{
+		Name:    "base",
+		Cluster: "cluster",
+		Machines: []SnapshotMachine{
+			{Hostname: "node0", Image: snapshotImage("cluster", "node0", "base")},
+		},
+	}
+	assert.NoError(t, s.save())
+
+	loaded, err := LoadSnapshot("cluster", "base")
+	assert.NoError(t, err)
+	assert.Equal(t, s.Machines, loaded.Machines)
+}
+
+func TestSetSnapshot(t *testing.T) {
+	c := newTestCluster(t, 2)
+
+	s := &Snapshot{
+		Name:    "base",
+		Cluster: "cluster",
+		Machines: []SnapshotMachine{
+			{Hostname: "group0-node0", Image: "footloose-snapshot/cluster-group0-node0:base"},
+		},
+	}
+	assert.Error(t, c.SetSnapshot(s))
+
+	s.Machines = append(s.Machines, SnapshotMachine{
+		Hostname: "group0-node1",
+		Image:    "footloose-snapshot/cluster-group0-node1:base",
+	})
+	assert.NoError(t, c.SetSnapshot(s))
+	assert.Equal(t, "footloose-snapshot/cluster-group0-node1:base", c.machineImage(c.machineJobs()[1].machine))
+
+	s.Cluster = "other"
+	assert.Error(t, c.SetSnapshot(s))
+}
@@ -0,0 +1,218 @@
+package cluster
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/weaveworks/footloose/pkg/config"
+	"github.com/weaveworks/footloose/pkg/docker"
+)
+
+// Labels recording the spec a machine was created from.
+const (
+	// specHashLabel is the SHA-256 of the serialized spec.
+	specHashLabel = "works.weave.spec-hash"
+	// specLabel is the JSON serialized spec.
+	specLabel = "works.weave.spec"
+)
+
+// containerSpec returns the part of spec baked into the machine container.
+// Other fields, eg. readiness checks or users, can change without recreating
+// the machine.
+func containerSpec(spec *config.Machine) *config.Machine {
+	return &config.Machine{
+		Name:         spec.Name,
+		Image:        spec.Image,
+		Privileged:   spec.Privileged,
+		Volumes:      spec.Volumes,
+		Networks:     spec.Networks,
+		PortMappings: spec.PortMappings,
+		Cmd:          spec.Cmd,
+		PublicKey:    spec.PublicKey,
+		Backend:      spec.Backend,
+		Ignite:       spec.Ignite,
+	}
+}
+
+// serializeSpec returns the JSON serialization of spec and its hash.
+func serializeSpec(spec *config.Machine) (data []byte, hash string, err error) {
+	data, err = json.Marshal(spec)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "serialize machine spec")
+	}
+	sum := sha256.Sum256(data)
+	return data, hex.EncodeToString(sum[:]), nil
+}
+
+// specLabels returns the docker run arguments labelling a container with the
+// container part of spec.
+func specLabels(spec *config.Machine) ([]string, error) {
+	data, hash, err := serializeSpec(containerSpec(spec))
+	if err != nil {
+		return nil, err
+	}
+	return []string{
+		"--label", specHashLabel + "=" + hash,
+		"--label", specLabel + "=" + string(data),
+	}, nil
+}
+
+// createdSpec returns the spec m was created from, and its hash. A nil spec is
+// returned for containers created before footloose recorded it.
+func (m *Machine) createdSpec() (*config.Machine, string, error) {
+	var labels map[string]string
+	if err := docker.InspectObject(m.name, ".Config.Labels", &labels); err != nil {
+		return nil, "", err
+	}
+	hash, ok := labels[specHashLabel]
+	if !ok {
+		return nil, "", nil
+	}
+	spec := &config.Machine{}
+	if err := json.Unmarshal([]byte(labels[specLabel]), spec); err != nil {
+		return nil, "", errors.Wrapf(err, "machine %s: invalid %s label", m.name, specLabel)
+	}
+	return spec, hash, nil
+}
+
+// specFields returns the serialized fields of spec.
+func specFields(spec *config.Machine) (map[string]interface{}, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func jsonString(v interface{}) string {
+	if v == nil {
+		return "<none>"
+	}
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+// specChanges returns the fields that differ between two specs, in the order
+// of their JSON name.
+func specChanges(from, to *config.Machine) ([]string, error) {
+	fromFields, err := specFields(from)
+	if err != nil {
+		return nil, err
+	}
+	toFields, err := specFields(to)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for name := range fromFields {
+		names = append(names, name)
+	}
+	for name := range toFields {
+		if _, ok := fromFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []string
+	for _, name := range names {
+		if reflect.DeepEqual(fromFields[name], toFields[name]) {
+			continue
+		}
+		changes = append(changes, f("%s: %s -> %s", name, jsonString(fromFields[name]), jsonString(toFields[name])))
+	}
+	return changes, nil
+}
+
+// machineChanges returns the differences between the spec of m and the spec
+// the container running it was created from. Containers created before
+// footloose recorded their spec are compared with what can be inspected.
+func machineChanges(m *Machine) ([]string, error) {
+	created, hash, err := m.createdSpec()
+	if err != nil {
+		return nil, err
+	}
+	if created == nil {
+		return inspectedMachineChanges(m)
+	}
+	current := containerSpec(m.spec)
+	_, currentHash, err := serializeSpec(current)
+	if err != nil {
+		return nil, err
+	}
+	if hash == currentHash {
+		return nil, nil
+	}
+	return specChanges(containerSpec(created), current)
+}
+
+// Diff states.
+const (
+	// DiffNotCreated is the state of machines not created yet.
+	DiffNotCreated = "not created"
+	// DiffUpToDate is the state of machines matching their spec.
+	DiffUpToDate = "up to date"
+	// DiffChanged is the state of machines which spec changed since their
+	// creation.
+	DiffChanged = "changed"
+	// DiffUnknown is the state of machines which can't be compared to their
+	// spec.
+	DiffUnknown = "unknown"
+)
+
+// MachineDiff describes how a machine differs from its spec.
+type MachineDiff struct {
+	// Machine is the machine hostname.
+	Machine string `json:"machine"`
+	// State is one of "not created", "up to date", "changed" or "unknown".
+	State string `json:"state"`
+	// Changes lists, for changed machines, the fields that differ.
+	Changes []string `json:"changes,omitempty"`
+}
+
+// Diff compares the given machines, or all the cluster machines if none are
+// given, with the cluster configuration.
+func (c *Cluster) Diff(hostnames []string) ([]*MachineDiff, error) {
+	if err := docker.IsRunning(); err != nil {
+		return nil, err
+	}
+	machines := c.gatherMachinesByCluster()
+	if len(hostnames) > 0 {
+		machines = c.machineFilering(machines, hostnames)
+	}
+
+	var diffs []*MachineDiff
+	for _, m := range machines {
+		diff := &MachineDiff{Machine: m.hostname}
+		diffs = append(diffs, diff)
+
+		if !m.IsCreated() {
+			diff.State = DiffNotCreated
+			continue
+		}
+		if m.IsIgnite() {
+			diff.State = DiffUnknown
+			continue
+		}
+		changes, err := machineChanges(m)
+		if err != nil {
+			return nil, err
+		}
+		diff.State = DiffUpToDate
+		if len(changes) > 0 {
+			diff.State = DiffChanged
+			diff.Changes = changes
+		}
+	}
+	return diffs, nil
+}
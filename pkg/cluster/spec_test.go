@@ -0,0 +1,79 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weaveworks/footloose/pkg/config"
+)
+
+func TestSerializeSpec(t *testing.T) {
+	spec := config.Machine{
+		Name:         "node%d",
+		Image:        "quay.io/footloose/centos7",
+		PortMappings: []config.PortMapping{{ContainerPort: 22}},
+	}
+	_, hash, err := serializeSpec(&spec)
+	assert.NoError(t, err)
+
+	same := spec
+	_, sameHash, err := serializeSpec(&same)
+	assert.NoError(t, err)
+	assert.Equal(t, hash, sameHash)
+
+	changed := spec
+	changed.Privileged = true
+	_, changedHash, err := serializeSpec(&changed)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, changedHash)
+}
+
+func TestContainerSpec(t *testing.T) {
+	spec := config.Machine{
+		Name:         "node%d",
+		Image:        "quay.io/footloose/centos7",
+		PortMappings: []config.PortMapping{{ContainerPort: 22}},
+	}
+	_, hash, err := serializeSpec(containerSpec(&spec))
+	assert.NoError(t, err)
+
+	provisioned := spec
+	provisioned.Users = []config.User{{Name: "alice"}}
+	provisioned.Readiness = []config.ReadinessCheck{{Type: "tcp", Port: 22}}
+	_, provisionedHash, err := serializeSpec(containerSpec(&provisioned))
+	assert.NoError(t, err)
+	assert.Equal(t, hash, provisionedHash)
+
+	changes, err := specChanges(containerSpec(&spec), containerSpec(&provisioned))
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestSpecChanges(t *testing.T) {
+	from := &config.Machine{
+		Name:         "node%d",
+		Image:        "quay.io/footloose/centos7",
+		PortMappings: []config.PortMapping{{ContainerPort: 22}},
+		Privileged:   true,
+	}
+	to := &config.Machine{
+		Name:         "node%d",
+		Image:        "quay.io/footloose/ubuntu18.04",
+		PortMappings: []config.PortMapping{{ContainerPort: 22}, {ContainerPort: 80}},
+		Networks:     []string{"footloose-net"},
+	}
+
+	changes, err := specChanges(from, to)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		`image: "quay.io/footloose/centos7" -> "quay.io/footloose/ubuntu18.04"`,
+		`networks: <none> -> ["footloose-net"]`,
+		`portMappings: [{"containerPort":22}] -> [{"containerPort":22},{"containerPort":80}]`,
+		`privileged: true -> <none>`,
+	}, changes)
+
+	changes, err = specChanges(from, from)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
@@ -0,0 +1,135 @@
+package cluster
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ed25519"
+	gossh "golang.org/x/crypto/ssh"
+
+	"github.com/weaveworks/footloose/pkg/config"
+)
+
+// generateSSHKey creates a new SSH key pair of the given type, writing the
+// private key at path and the public key at path.pub.
+func generateSSHKey(keyType, path, comment string) error {
+	var private *pem.Block
+	var public gossh.PublicKey
+
+	switch keyType {
+	case config.KeyTypeRSA, "":
+		key, err := rsa.GenerateKey(rand.Reader, 4096)
+		if err != nil {
+			return err
+		}
+		private = &pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(key),
+		}
+		if public, err = gossh.NewPublicKey(&key.PublicKey); err != nil {
+			return err
+		}
+	case config.KeyTypeECDSA:
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return err
+		}
+		der, err := x509.MarshalECPrivateKey(key)
+		if err != nil {
+			return err
+		}
+		private = &pem.Block{
+			Type:  "EC PRIVATE KEY",
+			Bytes: der,
+		}
+		if public, err = gossh.NewPublicKey(&key.PublicKey); err != nil {
+			return err
+		}
+	case config.KeyTypeED25519:
+		pub, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return err
+		}
+		if public, err = gossh.NewPublicKey(pub); err != nil {
+			return err
+		}
+		private = &pem.Block{
+			Type:  "OPENSSH PRIVATE KEY",
+			Bytes: marshalED25519PrivateKey(public, priv, comment),
+		}
+	default:
+		return errors.Errorf("unknown SSH key type '%s'", keyType)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, pem.EncodeToMemory(private), 0600); err != nil {
+		return errors.Wrap(err, "write private key")
+	}
+	authorizedKey := strings.TrimSpace(string(gossh.MarshalAuthorizedKey(public)))
+	authorizedKey += " " + comment + "\n"
+	if err := ioutil.WriteFile(path+".pub", []byte(authorizedKey), 0644); err != nil {
+		return errors.Wrap(err, "write public key")
+	}
+	return nil
+}
+
+// marshalED25519PrivateKey serializes an ed25519 private key in the OpenSSH
+// format, the only format OpenSSH understands for those keys. See:
+// https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.key
+func marshalED25519PrivateKey(public gossh.PublicKey, key ed25519.PrivateKey, comment string) []byte {
+	const magic = "openssh-key-v1\x00"
+
+	var check [4]byte
+	_, _ = rand.Read(check[:])
+	checkInt := binary.BigEndian.Uint32(check[:])
+
+	privateBlock := struct {
+		Check1  uint32
+		Check2  uint32
+		Keytype string
+		Pub     []byte
+		Priv    []byte
+		Comment string
+		Pad     []byte `ssh:"rest"`
+	}{
+		Check1:  checkInt,
+		Check2:  checkInt,
+		Keytype: gossh.KeyAlgoED25519,
+		Pub:     key.Public().(ed25519.PublicKey),
+		Priv:    key,
+		Comment: comment,
+	}
+	// The private section is padded to the cipher block size, 8 for "none".
+	for i := 1; len(gossh.Marshal(privateBlock))%8 != 0; i++ {
+		privateBlock.Pad = append(privateBlock.Pad, byte(i))
+	}
+
+	w := struct {
+		CipherName   string
+		KdfName      string
+		KdfOpts      string
+		NumKeys      uint32
+		PubKey       []byte
+		PrivKeyBlock []byte
+	}{
+		CipherName:   "none",
+		KdfName:      "none",
+		NumKeys:      1,
+		PubKey:       public.Marshal(),
+		PrivKeyBlock: gossh.Marshal(privateBlock),
+	}
+
+	return append([]byte(magic), gossh.Marshal(w)...)
+}
@@ -0,0 +1,45 @@
+package cluster
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	gossh "golang.org/x/crypto/ssh"
+
+	"github.com/weaveworks/footloose/pkg/config"
+)
+
+func TestGenerateSSHKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "footloose-key")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	for _, keyType := range []string{config.KeyTypeRSA, config.KeyTypeECDSA, config.KeyTypeED25519} {
+		t.Run(keyType, func(t *testing.T) {
+			path := filepath.Join(dir, keyType)
+			err := generateSSHKey(keyType, path, "cluster@footloose.mail")
+			assert.NoError(t, err)
+
+			info, err := os.Stat(path)
+			assert.NoError(t, err)
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+			data, err := ioutil.ReadFile(path)
+			assert.NoError(t, err)
+			signer, err := gossh.ParsePrivateKey(data)
+			assert.NoError(t, err)
+
+			data, err = ioutil.ReadFile(path + ".pub")
+			assert.NoError(t, err)
+			public, comment, _, _, err := gossh.ParseAuthorizedKey(data)
+			assert.NoError(t, err)
+			assert.Equal(t, "cluster@footloose.mail", comment)
+			assert.Equal(t, signer.PublicKey().Marshal(), public.Marshal())
+		})
+	}
+
+	assert.Error(t, generateSSHKey("dsa", filepath.Join(dir, "dsa"), ""))
+}
@@ -0,0 +1,266 @@
+package cluster
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+
+	"github.com/weaveworks/footloose/pkg/config"
+	"github.com/weaveworks/footloose/pkg/docker"
+	"github.com/weaveworks/footloose/pkg/ignite"
+)
+
+// State is what footloose records about a cluster on the host. It outlives
+// changes to the cluster configuration file, or the file itself.
+type State struct {
+	// Name is the cluster name.
+	Name string `json:"name"`
+	// Created is the time the first machine of the cluster was created.
+	Created time.Time `json:"created"`
+	// ConfigPath is the absolute path of the configuration file the cluster
+	// was created from, if known.
+	ConfigPath string `json:"configPath,omitempty"`
+	// PrivateKey is the absolute path of the cluster SSH private key.
+	PrivateKey string `json:"privateKey,omitempty"`
+	// Config is the cluster configuration at the time machines were last
+	// created.
+	Config config.Config `json:"config"`
+	// Machines lists the machines of the cluster.
+	Machines []MachineState `json:"machines"`
+	// Deadline is when the cluster expires, once its TTL has been extended
+	// with `footloose extend`.
+	Deadline *time.Time `json:"deadline,omitempty"`
+}
+
+// MachineState is what footloose records about a machine.
+type MachineState struct {
+	// Name is the container (or VM) name.
+	Name string `json:"name"`
+	// Hostname is the machine hostname.
+	Hostname string `json:"hostname"`
+	// ID is the container (or VM) ID.
+	ID string `json:"id,omitempty"`
+	// Created is the time the machine was created.
+	Created time.Time `json:"created"`
+	// Spec is the spec the machine was created from.
+	Spec config.Machine `json:"spec"`
+}
+
+// stateLock serializes state updates.
+var stateLock sync.Mutex
+
+// stateDir returns the directory holding the state of a cluster.
+func stateDir(name string) (string, error) {
+	dir, err := footlooseDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "clusters", name), nil
+}
+
+func statePath(name string) (string, error) {
+	dir, err := stateDir(name)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "state.json"), nil
+}
+
+// LoadState reads the state of the cluster name. It returns nil, and no
+// error, when footloose has no state for this cluster.
+func LoadState(name string) (*State, error) {
+	path, err := statePath(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := &State{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, errors.Wrapf(err, "cluster state %s", path)
+	}
+	return state, nil
+}
+
+// save atomically writes the state.
+func (s *State) save() error {
+	path, err := statePath(s.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *State) removeMachine(hostname string) {
+	var machines []MachineState
+	for _, m := range s.Machines {
+		if m.Hostname != hostname {
+			machines = append(machines, m)
+		}
+	}
+	s.Machines = machines
+}
+
+// updateState applies update to the cluster state, creating it if necessary.
+func (c *Cluster) updateState(update func(*State)) error {
+	stateLock.Lock()
+	defer stateLock.Unlock()
+
+	state, err := LoadState(c.spec.Cluster.Name)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		state = &State{
+			Name:    c.spec.Cluster.Name,
+			Created: time.Now().UTC(),
+		}
+	}
+	update(state)
+	return state.save()
+}
+
+// machineID returns the ID of the container or VM running m.
+func machineID(m *Machine) string {
+	if m.IsIgnite() {
+		vm, err := ignite.PopulateMachineDetails(m.name)
+		if err != nil {
+			return ""
+		}
+		return vm.Metadata.UID
+	}
+	lines, err := docker.Inspect(m.name, "{{.Id}}")
+	if err != nil || len(lines) != 1 {
+		return ""
+	}
+	return lines[0]
+}
+
+// recordMachine records a freshly created machine in the cluster state.
+func (c *Cluster) recordMachine(m *Machine) error {
+	machine := MachineState{
+		Name:     m.name,
+		Hostname: m.hostname,
+		ID:       machineID(m),
+		Created:  time.Now().UTC(),
+		Spec:     *m.spec,
+	}
+	privateKey, _ := homedir.Expand(c.spec.Cluster.PrivateKey)
+	if privateKey != "" {
+		privateKey, _ = filepath.Abs(privateKey)
+	}
+
+	return c.updateState(func(s *State) {
+		s.Config = c.spec
+		s.PrivateKey = privateKey
+		if c.configPath != "" {
+			s.ConfigPath = c.configPath
+		}
+		s.removeMachine(m.hostname)
+		s.Machines = append(s.Machines, machine)
+	})
+}
+
+// forgetMachine removes a deleted machine from the cluster state.
+func (c *Cluster) forgetMachine(m *Machine) error {
+	stateLock.Lock()
+	defer stateLock.Unlock()
+
+	state, err := LoadState(c.spec.Cluster.Name)
+	if err != nil || state == nil {
+		return err
+	}
+	state.removeMachine(m.hostname)
+	return state.save()
+}
+
+// removeState removes the cluster state if it doesn't have any machine left.
+func (c *Cluster) removeState() error {
+	stateLock.Lock()
+	defer stateLock.Unlock()
+
+	state, err := LoadState(c.spec.Cluster.Name)
+	if err != nil || state == nil {
+		return err
+	}
+	if len(state.Machines) > 0 {
+		return nil
+	}
+	dir, err := stateDir(c.spec.Cluster.Name)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// NewFromState creates a Cluster from the recorded state of the cluster name,
+// for when its configuration file has been lost or moved.
+func NewFromState(name string) (*Cluster, error) {
+	state, err := LoadState(name)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, errors.Errorf("no state recorded for cluster %s", name)
+	}
+	conf := state.Config
+	if state.PrivateKey != "" {
+		conf.Cluster.PrivateKey = state.PrivateKey
+	}
+	c, err := New(conf)
+	if err != nil {
+		return nil, err
+	}
+	c.configPath = state.ConfigPath
+	return c, nil
+}
+
+// stateMachines returns the machines recorded in the cluster state but not
+// described by the cluster configuration any more.
+func (c *Cluster) stateMachines() []*Machine {
+	state, err := LoadState(c.spec.Cluster.Name)
+	if err != nil || state == nil {
+		return nil
+	}
+
+	current := make(map[string]bool)
+	for _, job := range c.machineJobs() {
+		current[job.machine.hostname] = true
+	}
+
+	var machines []*Machine
+	for i := range state.Machines {
+		ms := &state.Machines[i]
+		if current[ms.Hostname] {
+			continue
+		}
+		machines = append(machines, &Machine{
+			spec:     &ms.Spec,
+			name:     ms.Name,
+			hostname: ms.Hostname,
+		})
+	}
+	return machines
+}
@@ -0,0 +1,53 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestState(t *testing.T) {
+	defer withHome(t)()
+
+	c := newTestCluster(t, 2)
+	c.spec.Cluster.PrivateKey = "/keys/cluster-key"
+	jobs := c.machineJobs()
+	for _, job := range jobs {
+		assert.NoError(t, c.recordMachine(job.machine))
+	}
+
+	state, err := LoadState("cluster")
+	assert.NoError(t, err)
+	assert.Equal(t, "/keys/cluster-key", state.PrivateKey)
+	assert.Len(t, state.Machines, 2)
+	assert.Equal(t, "group0-node1", state.Machines[1].Hostname)
+	assert.Equal(t, "cluster-group0-node1", state.Machines[1].Name)
+
+	// The cluster is recreated from its state.
+	fromState, err := NewFromState("cluster")
+	assert.NoError(t, err)
+	assert.Equal(t, c.spec, fromState.spec)
+
+	// Machines removed from the configuration are still known.
+	c.spec.Machines[0].Count = 1
+	machines := c.stateMachines()
+	assert.Len(t, machines, 1)
+	assert.Equal(t, "group0-node1", machines[0].hostname)
+
+	m, err := c.machineFromHostname("group0-node1")
+	assert.NoError(t, err)
+	assert.Equal(t, "cluster-group0-node1", m.name)
+
+	// The state is removed with the last machine.
+	assert.NoError(t, c.forgetMachine(jobs[0].machine))
+	assert.NoError(t, c.removeState())
+	state, err = LoadState("cluster")
+	assert.NoError(t, err)
+	assert.NotNil(t, state)
+
+	assert.NoError(t, c.forgetMachine(jobs[1].machine))
+	assert.NoError(t, c.removeState())
+	state, err = LoadState("cluster")
+	assert.NoError(t, err)
+	assert.Nil(t, state)
+}
@@ -0,0 +1,244 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/weaveworks/footloose/pkg/docker"
+)
+
+// MachineStats is the resource usage of a machine. Sizes are in bytes.
+//
+// Ignite machines report the usage of the container running their VM
+// process.
+type MachineStats struct {
+	// Machine is the machine hostname.
+	Machine string `json:"machine"`
+	// Container is the name of the container running the machine.
+	Container   string  `json:"container"`
+	CPUPercent  float64 `json:"cpuPercent"`
+	MemoryUsage int64   `json:"memoryUsage"`
+	MemoryLimit int64   `json:"memoryLimit"`
+	NetworkRx   int64   `json:"networkRx"`
+	NetworkTx   int64   `json:"networkTx"`
+	BlockRead   int64   `json:"blockRead"`
+	BlockWrite  int64   `json:"blockWrite"`
+	PIDs        int     `json:"pids"`
+}
+
+// parseIOPair parses the "used / total" or "in / out" sizes of docker stats.
+func parseIOPair(s string, parse func(string) (int64, error)) (int64, int64, error) {
+	parts := strings.Split(s, " / ")
+	if len(parts) != 2 {
+		return 0, 0, errors.Errorf("invalid value '%s'", s)
+	}
+	a, err := parse(parts[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	b, err := parse(parts[1])
+	if err != nil {
+		return 0, 0, err
+	}
+	return a, b, nil
+}
+
+// parseContainerStats converts docker stats to machine stats.
+func parseContainerStats(s *docker.ContainerStats) (*MachineStats, error) {
+	stats := &MachineStats{}
+	cpu, err := strconv.ParseFloat(strings.TrimSuffix(s.CPUPerc, "%"), 64)
+	if err != nil {
+		return nil, errors.Errorf("invalid CPU usage '%s'", s.CPUPerc)
+	}
+	stats.CPUPercent = cpu
+	// docker stats formats memory with binary units, I/O with decimal units.
+	if stats.MemoryUsage, stats.MemoryLimit, err = parseIOPair(s.MemUsage, units.RAMInBytes); err != nil {
+		return nil, errors.Wrap(err, "memory usage")
+	}
+	if stats.NetworkRx, stats.NetworkTx, err = parseIOPair(s.NetIO, units.FromHumanSize); err != nil {
+		return nil, errors.Wrap(err, "network I/O")
+	}
+	if stats.BlockRead, stats.BlockWrite, err = parseIOPair(s.BlockIO, units.FromHumanSize); err != nil {
+		return nil, errors.Wrap(err, "block I/O")
+	}
+	if stats.PIDs, err = strconv.Atoi(s.PIDs); err != nil {
+		return nil, errors.Errorf("invalid PIDs '%s'", s.PIDs)
+	}
+	return stats, nil
+}
+
+// machinesStats returns the stats of machines, which must be running.
+func machinesStats(machines []*Machine) ([]*MachineStats, error) {
+	if len(machines) == 0 {
+		return nil, nil
+	}
+	var containers []string
+	for _, m := range machines {
+		containers = append(containers, m.ContainerName())
+	}
+	raw, err := docker.Stats(containers...)
+	if err != nil {
+		return nil, err
+	}
+	return collectStats(machines, containers, raw), nil
+}
+
+// collectStats returns the stats of machines, running in containers, found in
+// raw, in the order of machines. Machines stopping while docker gathers stats are reported with
+// "--" values: they're left out rather than failing the whole call.
+func collectStats(machines []*Machine, containers []string, raw []docker.ContainerStats) []*MachineStats {
+	byContainer := make(map[string]*docker.ContainerStats)
+	for i := range raw {
+		byContainer[raw[i].Container] = &raw[i]
+	}
+
+	var stats []*MachineStats
+	for i, m := range machines {
+		s, ok := byContainer[containers[i]]
+		if !ok {
+			log.Debugf("%s: no stats reported", m.hostname)
+			continue
+		}
+		machineStats, err := parseContainerStats(s)
+		if err != nil {
+			log.Debugf("%s: %v", m.hostname, err)
+			continue
+		}
+		machineStats.Machine = m.hostname
+		machineStats.Container = m.name
+		stats = append(stats, machineStats)
+	}
+	return stats
+}
+
+// Stats returns the current resource usage of the machine. The machine must be
+// running.
+func (m *Machine) Stats() (*MachineStats, error) {
+	if !m.IsStarted() {
+		return nil, errors.Errorf("%s: machine isn't running", m.hostname)
+	}
+	stats, err := machinesStats([]*Machine{m})
+	if err != nil {
+		return nil, err
+	}
+	if len(stats) == 0 {
+		return nil, errors.Errorf("%s: no stats reported", m.hostname)
+	}
+	return stats[0], nil
+}
+
+// runningMachines returns the running machines with the given hostnames, or
+// all the running machines of the cluster.
+func (c *Cluster) runningMachines(hostnames []string) ([]*Machine, error) {
+	machines, err := c.selectMachines(hostnames)
+	if err != nil {
+		return nil, err
+	}
+	var running []*Machine
+	for _, m := range machines {
+		if !m.IsStarted() {
+			if len(hostnames) > 0 {
+				log.Warnf("machine %s isn't running", m.hostname)
+			}
+			continue
+		}
+		running = append(running, m)
+	}
+	return running, nil
+}
+
+// Stats returns the current resource usage of the running machines with the
+// given hostnames, or of all the running machines.
+func (c *Cluster) Stats(hostnames []string) ([]*MachineStats, error) {
+	if err := docker.IsRunning(); err != nil {
+		return nil, err
+	}
+	machines, err := c.runningMachines(hostnames)
+	if err != nil {
+		return nil, err
+	}
+	return machinesStats(machines)
+}
+
+// StreamStats sends the resource usage of the machines with the given
+// hostnames, or of all machines, to stats every interval until ctx is done or
+// gathering stats fails. Machines started or stopped after the stream started
+// are picked up at the next interval.
+//
+// StreamStats blocks, it's meant to be run in its own goroutine. It doesn't
+// close stats.
+func (c *Cluster) StreamStats(ctx context.Context, hostnames []string, interval time.Duration, stats chan<- []*MachineStats) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		s, err := c.Stats(hostnames)
+		if err != nil {
+			return err
+		}
+		select {
+		case stats <- s:
+		case <-ctx.Done():
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// StatsFormatter formats machine stats.
+type StatsFormatter interface {
+	FormatStats(io.Writer, []*MachineStats) error
+}
+
+// JSONStatsFormatter formats stats as JSON.
+type JSONStatsFormatter struct{}
+
+// TableStatsFormatter formats stats as a table.
+type TableStatsFormatter struct{}
+
+// FormatStats writes stats as a JSON object.
+func (JSONStatsFormatter) FormatStats(w io.Writer, stats []*MachineStats) error {
+	s := struct {
+		Machines []*MachineStats `json:"machines"`
+	}{
+		Machines: stats,
+	}
+	if s.Machines == nil {
+		s.Machines = []*MachineStats{}
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	_, err = w.Write(data)
+	return err
+}
+
+// FormatStats writes stats as a table.
+func (TableStatsFormatter) FormatStats(w io.Writer, stats []*MachineStats) error {
+	table := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(table, "HOSTNAME\tCPU %\tMEM USAGE / LIMIT\tNET I/O\tBLOCK I/O\tPIDS")
+	for _, s := range stats {
+		fmt.Fprintf(table, "%s\t%.2f%%\t%s / %s\t%s / %s\t%s / %s\t%d\n",
+			s.Machine, s.CPUPercent,
+			units.BytesSize(float64(s.MemoryUsage)), units.BytesSize(float64(s.MemoryLimit)),
+			units.HumanSize(float64(s.NetworkRx)), units.HumanSize(float64(s.NetworkTx)),
+			units.HumanSize(float64(s.BlockRead)), units.HumanSize(float64(s.BlockWrite)),
+			s.PIDs)
+	}
+	return table.Flush()
+}
@@ -0,0 +1,75 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weaveworks/footloose/pkg/docker"
+)
+
+func TestParseContainerStats(t *testing.T) {
+	stats, err := parseContainerStats(&docker.ContainerStats{
+		CPUPerc:  "12.50%",
+		MemUsage: "64MiB / 1GiB",
+		NetIO:    "1.5kB / 0B",
+		BlockIO:  "2MB / 3.1MB",
+		PIDs:     "17",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &MachineStats{
+		CPUPercent:  12.5,
+		MemoryUsage: 64 * 1024 * 1024,
+		MemoryLimit: 1024 * 1024 * 1024,
+		NetworkRx:   1500,
+		NetworkTx:   0,
+		BlockRead:   2000000,
+		BlockWrite:  3100000,
+		PIDs:        17,
+	}, stats)
+
+	_, err = parseContainerStats(&docker.ContainerStats{
+		CPUPerc:  "--",
+		MemUsage: "-- / --",
+		NetIO:    "-- / --",
+		BlockIO:  "-- / --",
+		PIDs:     "--",
+	})
+	assert.Error(t, err)
+	_, err = parseContainerStats(&docker.ContainerStats{
+		CPUPerc:  "1%",
+		MemUsage: "64MiB",
+		NetIO:    "0B / 0B",
+		BlockIO:  "0B / 0B",
+		PIDs:     "1",
+	})
+	assert.Error(t, err)
+}
+
+func TestCollectStats(t *testing.T) {
+	machines := []*Machine{
+		{name: "cluster-node0", hostname: "node0"},
+		{name: "cluster-node1", hostname: "node1"},
+		{name: "cluster-node2", hostname: "node2"},
+	}
+	containers := []string{"cluster-node0", "cluster-node1", "cluster-node2"}
+	stats := collectStats(machines, containers, []docker.ContainerStats{{
+		Container: "cluster-node0",
+		CPUPerc:   "1%",
+		MemUsage:  "64MiB / 1GiB",
+		NetIO:     "0B / 0B",
+		BlockIO:   "0B / 0B",
+		PIDs:      "1",
+	}, {
+		// node1 stopped while docker gathered stats.
+		Container: "cluster-node1",
+		CPUPerc:   "--",
+		MemUsage:  "-- / --",
+		NetIO:     "-- / --",
+		BlockIO:   "-- / --",
+		PIDs:      "--",
+	}})
+	assert.Len(t, stats, 1)
+	assert.Equal(t, "node0", stats[0].Machine)
+	assert.Equal(t, "cluster-node0", stats[0].Container)
+}
@@ -0,0 +1,292 @@
+package cluster
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+
+	"github.com/weaveworks/footloose/pkg/config"
+	"github.com/weaveworks/footloose/pkg/exec"
+)
+
+// footloose interprets the following subset of the cloud-init #cloud-config
+// format:
+//
+//   write_files: path, content, encoding (b64, gzip, gz+b64), owner,
+//                permissions, append
+//   users:       name, groups, sudo, shell, uid, ssh_authorized_keys. The
+//                "default" user is ignored.
+//   packages:    installed with apt-get, dnf, yum, apk or zypper, "name" or
+//                ["name", "version"]
+//   runcmd:      shell strings or argument lists
+//
+// Modules are applied in the order cloud-init runs them: write_files, users,
+// packages and runcmd. Other keys are ignored.
+
+const cloudConfigHeader = "#cloud-config"
+
+type cloudFile struct {
+	Path        string `json:"path"`
+	Content     string `json:"content"`
+	Encoding    string `json:"encoding"`
+	Owner       string `json:"owner"`
+	Permissions string `json:"permissions"`
+	Append      bool   `json:"append"`
+}
+
+type cloudConfig struct {
+	WriteFiles []cloudFile   `json:"write_files"`
+	Users      []interface{} `json:"users"`
+	Packages   []interface{} `json:"packages"`
+	RunCmd     []interface{} `json:"runcmd"`
+}
+
+// parseCloudConfig parses #cloud-config user data.
+func parseCloudConfig(data []byte) (*cloudConfig, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte(cloudConfigHeader)) {
+		return nil, errors.Errorf("user data doesn't start with '%s'", cloudConfigHeader)
+	}
+	conf := &cloudConfig{}
+	if err := yaml.Unmarshal(data, conf); err != nil {
+		return nil, errors.Wrap(err, "user data")
+	}
+	return conf, nil
+}
+
+// decodeContent decodes the content of a write_files entry.
+func decodeContent(file *cloudFile) ([]byte, error) {
+	content := []byte(file.Content)
+	switch strings.ToLower(file.Encoding) {
+	case "", "text/plain":
+		return content, nil
+	case "b64", "base64":
+		return base64.StdEncoding.DecodeString(file.Content)
+	case "gz", "gzip":
+	case "gz+b64", "gz+base64", "gzip+b64", "gzip+base64":
+		decoded, err := base64.StdEncoding.DecodeString(file.Content)
+		if err != nil {
+			return nil, err
+		}
+		content = decoded
+	default:
+		return nil, errors.Errorf("unknown encoding '%s'", file.Encoding)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+// writeFileScript writes its standard input to $1, appending to it when $2 is
+// "append", and sets its owner to $3 and its mode to $4, when given. Contents
+// are piped rather than inlined in scripts, so large files don't hit the
+// argument size limit.
+const writeFileScript = `set -e
+mkdir -p "$(dirname "$1")"
+if [ "$2" = append ]; then cat >> "$1"; else cat > "$1"; fi
+chown "$3" "$1"
+if [ -n "$4" ]; then chmod "$4" "$1"; fi
+`
+
+// fileWrite is a decoded write_files entry.
+type fileWrite struct {
+	// args are the arguments of writeFileScript.
+	args    []string
+	content []byte
+}
+
+// fileWrites decodes the write_files entries.
+func (conf *cloudConfig) fileWrites() ([]fileWrite, error) {
+	var writes []fileWrite
+	for i := range conf.WriteFiles {
+		file := &conf.WriteFiles[i]
+		if file.Path == "" {
+			return nil, errors.New("write_files: missing path")
+		}
+		content, err := decodeContent(file)
+		if err != nil {
+			return nil, errors.Wrapf(err, "write_files %s", file.Path)
+		}
+		mode := "write"
+		if file.Append {
+			mode = "append"
+		}
+		owner := file.Owner
+		if owner == "" {
+			owner = "root:root"
+		}
+		writes = append(writes, fileWrite{
+			args:    []string{file.Path, mode, owner, strings.TrimPrefix(file.Permissions, "0o")},
+			content: content,
+		})
+	}
+	return writes, nil
+}
+
+// writeFiles writes files in m.
+func writeFiles(m *Machine, writes []fileWrite) error {
+	for _, w := range writes {
+		args := append([]string{"-c", writeFileScript, "sh"}, w.args...)
+		cmd := m.cmder().Command(machineShell, args...)
+		cmd.SetStdin(bytes.NewReader(w.content))
+		output, err := exec.CombinedOutputLines(cmd)
+		if err != nil {
+			for _, line := range output {
+				m.logger().Error(line)
+			}
+			return errors.Wrapf(err, "write_files %s", w.args[0])
+		}
+	}
+	return nil
+}
+
+func (conf *cloudConfig) usersScript(s *strings.Builder) error {
+	users, err := config.CloudConfigUsers(conf.Users)
+	if err != nil {
+		return err
+	}
+	for i := range users {
+		u := &users[i]
+		keys := strings.Join(u.AuthorizedKeys, "\n")
+		s.WriteString("(\n")
+		s.WriteString(userScript(&u.User, []byte(keys)))
+		if len(u.SudoRules) > 0 {
+			s.WriteString("mkdir -p /etc/sudoers.d\n")
+			s.WriteString(f("cat <<'__EOF' > /etc/sudoers.d/%s\n", u.Name))
+			for _, rule := range u.SudoRules {
+				s.WriteString(f("%s %s\n", u.Name, rule))
+			}
+			s.WriteString("__EOF\n")
+			s.WriteString(f("chmod 440 /etc/sudoers.d/%s\n", u.Name))
+		}
+		s.WriteString(")\n")
+	}
+	return nil
+}
+
+// packagesScript installs packages with the first package manager found.
+func (conf *cloudConfig) packagesScript(s *strings.Builder) error {
+	if len(conf.Packages) == 0 {
+		return nil
+	}
+
+	// Package names, formatted for each package manager.
+	var apt, rpm, apk []string
+	for _, entry := range conf.Packages {
+		switch p := entry.(type) {
+		case string:
+			apt = append(apt, p)
+			rpm = append(rpm, p)
+			apk = append(apk, p)
+		case []interface{}:
+			if len(p) != 2 {
+				return errors.Errorf("packages: invalid entry %v", p)
+			}
+			name, version := fmt.Sprint(p[0]), fmt.Sprint(p[1])
+			apt = append(apt, name+"="+version)
+			rpm = append(rpm, name+"-"+version)
+			apk = append(apk, name+"="+version)
+		default:
+			return errors.Errorf("packages: invalid entry %v", p)
+		}
+	}
+	quote := func(packages []string) string {
+		var quoted []string
+		for _, p := range packages {
+			quoted = append(quoted, shellQuote(p))
+		}
+		return strings.Join(quoted, " ")
+	}
+
+	s.WriteString("if command -v apt-get >/dev/null 2>&1; then\n")
+	s.WriteString("  export DEBIAN_FRONTEND=noninteractive\n")
+	s.WriteString("  apt-get update -q\n")
+	s.WriteString(f("  apt-get install -q -y %s\n", quote(apt)))
+	s.WriteString("elif command -v dnf >/dev/null 2>&1; then\n")
+	s.WriteString(f("  dnf install -y %s\n", quote(rpm)))
+	s.WriteString("elif command -v yum >/dev/null 2>&1; then\n")
+	s.WriteString(f("  yum install -y %s\n", quote(rpm)))
+	s.WriteString("elif command -v zypper >/dev/null 2>&1; then\n")
+	s.WriteString(f("  zypper --non-interactive install %s\n", quote(rpm)))
+	s.WriteString("elif command -v apk >/dev/null 2>&1; then\n")
+	s.WriteString(f("  apk add %s\n", quote(apk)))
+	s.WriteString("else\n")
+	s.WriteString("  echo 'packages: no supported package manager found' >&2; exit 1\n")
+	s.WriteString("fi\n")
+	return nil
+}
+
+func (conf *cloudConfig) runCmdScript(s *strings.Builder) error {
+	for _, entry := range conf.RunCmd {
+		switch cmd := entry.(type) {
+		case string:
+			s.WriteString(cmd + "\n")
+		case []interface{}:
+			var args []string
+			for _, arg := range cmd {
+				args = append(args, shellQuote(fmt.Sprint(arg)))
+			}
+			s.WriteString(strings.Join(args, " ") + "\n")
+		default:
+			return errors.Errorf("runcmd: invalid entry %v", cmd)
+		}
+	}
+	return nil
+}
+
+// script returns the shell script applying the cloud-config modules but
+// write_files, see fileWrites.
+func (conf *cloudConfig) script() (string, error) {
+	var s strings.Builder
+	s.WriteString("set -e\n")
+	for _, module := range []func(*strings.Builder) error{
+		conf.usersScript,
+		conf.packagesScript,
+		conf.runCmdScript,
+	} {
+		if err := module(&s); err != nil {
+			return "", err
+		}
+	}
+	return s.String(), nil
+}
+
+// applyUserData applies the cloud-config user data of m.
+func applyUserData(m *Machine) error {
+	if m.spec.UserData == "" {
+		return nil
+	}
+	path, err := homedir.Expand(m.spec.UserData)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return errors.Wrap(err, "user data")
+	}
+	conf, err := parseCloudConfig(data)
+	if err != nil {
+		return errors.Wrap(err, path)
+	}
+	writes, err := conf.fileWrites()
+	if err != nil {
+		return errors.Wrapf(err, "%s", path)
+	}
+	script, err := conf.script()
+	if err != nil {
+		return errors.Wrapf(err, "%s", path)
+	}
+	m.logger().Infof("Applying user data %s to machine %s ...", m.spec.UserData, m.name)
+	if err := writeFiles(m, writes); err != nil {
+		return err
+	}
+	return machineRunShell(m, script)
+}
@@ -0,0 +1,136 @@
+package cluster
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testUserData = `#cloud-config
+write_files:
+- path: /etc/app/app.conf
+  content: |
+    listen: 8080
+  permissions: '0640'
+  owner: app:app
+- path: /etc/motd
+  content: d2VsY29tZQo=
+  encoding: b64
+  append: true
+users:
+- default
+- name: alice
+  groups: [adm, wheel]
+  sudo: ALL=(ALL) NOPASSWD:ALL
+  uid: 1500
+  ssh_authorized_keys:
+  - ssh-ed25519 AAAA alice@laptop
+packages:
+- curl
+- [nginx, 1.14.0]
+runcmd:
+- systemctl enable --now nginx
+- [sh, -c, "echo it's done"]
+`
+
+func TestParseCloudConfig(t *testing.T) {
+	_, err := parseCloudConfig([]byte("write_files: []\n"))
+	assert.Error(t, err, "missing header")
+
+	conf, err := parseCloudConfig([]byte(testUserData))
+	assert.NoError(t, err)
+	assert.Len(t, conf.WriteFiles, 2)
+	assert.Len(t, conf.Users, 2)
+}
+
+func TestCloudConfigScript(t *testing.T) {
+	conf, err := parseCloudConfig([]byte(testUserData))
+	assert.NoError(t, err)
+	script, err := conf.script()
+	assert.NoError(t, err)
+
+	for _, expected := range []string{
+		"alice ALL=(ALL) NOPASSWD:ALL\n",
+		"apt-get install -q -y 'curl' 'nginx=1.14.0'\n",
+		"yum install -y 'curl' 'nginx-1.14.0'\n",
+		"systemctl enable --now nginx\n",
+		`'sh' '-c' 'echo it'\''s done'` + "\n",
+	} {
+		assert.Contains(t, script, expected)
+	}
+
+	// Modules are applied in cloud-init order, write_files being applied
+	// before the script.
+	assert.NotContains(t, script, "/etc/motd")
+	users := strings.Index(script, "name='alice'")
+	packages := strings.Index(script, "apt-get")
+	runcmd := strings.Index(script, "systemctl enable")
+	assert.True(t, users < packages && packages < runcmd)
+}
+
+func TestCloudConfigFileWrites(t *testing.T) {
+	conf, err := parseCloudConfig([]byte(testUserData))
+	assert.NoError(t, err)
+	writes, err := conf.fileWrites()
+	assert.NoError(t, err)
+	assert.Equal(t, []fileWrite{{
+		args:    []string{"/etc/app/app.conf", "write", "app:app", "0640"},
+		content: []byte("listen: 8080\n"),
+	}, {
+		args:    []string{"/etc/motd", "append", "root:root", ""},
+		content: []byte("welcome\n"),
+	}}, writes)
+
+	conf.WriteFiles = append(conf.WriteFiles, cloudFile{Content: "x"})
+	_, err = conf.fileWrites()
+	assert.Error(t, err)
+}
+
+// TestWriteFileScript runs writeFileScript locally with content larger than
+// what fits in command arguments.
+func TestWriteFileScript(t *testing.T) {
+	dir, err := ioutil.TempDir("", "footloose-write-files")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "etc", "large")
+	content := bytes.Repeat([]byte("0123456789abcdef"), 256*1024)
+	owner := fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())
+	cmd := osexec.Command("/bin/sh", "-c", writeFileScript, "sh", path, "write", owner, "0600")
+	cmd.Stdin = bytes.NewReader(content)
+	output, err := cmd.CombinedOutput()
+	assert.NoError(t, err, string(output))
+
+	written, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, content, written)
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestDecodeContent(t *testing.T) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte("hello\n"))
+	w.Close()
+
+	content, err := decodeContent(&cloudFile{
+		Content:  base64.StdEncoding.EncodeToString(buf.Bytes()),
+		Encoding: "gz+b64",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "hello\n", string(content))
+
+	_, err = decodeContent(&cloudFile{Content: "x", Encoding: "rot13"})
+	assert.Error(t, err)
+}
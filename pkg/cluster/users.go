@@ -0,0 +1,169 @@
+package cluster
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+
+	"github.com/weaveworks/footloose/pkg/config"
+)
+
+// machineUsers returns the list of users to create in m.
+func machineUsers(m *Machine) ([]config.User, error) {
+	users := m.spec.Users
+	if m.spec.HostUser {
+		u, err := config.HostUser()
+		if err != nil {
+			return nil, err
+		}
+		// root already exists.
+		if u.Name != "root" {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+// userAuthorizedKeys returns the content of the authorized_keys file for u.
+func (c *Cluster) userAuthorizedKeys(m *Machine, u *config.User) ([]byte, error) {
+	if len(u.PublicKeys) == 0 && len(u.PublicKeyFiles) == 0 {
+		return c.publicKey(m)
+	}
+
+	var keys bytes.Buffer
+	for _, name := range u.PublicKeys {
+		if c.keyStore == nil {
+			return nil, errors.Errorf("user %s: no key store to retrieve key '%s' from", u.Name, name)
+		}
+		data, err := c.keyStore.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		keys.WriteString(strings.TrimSpace(string(data)) + "\n")
+	}
+	for _, file := range u.PublicKeyFiles {
+		path, err := homedir.Expand(file)
+		if err != nil {
+			return nil, err
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "user %s", u.Name)
+		}
+		keys.WriteString(strings.TrimSpace(string(data)) + "\n")
+	}
+	return keys.Bytes(), nil
+}
+
+// shellQuote quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// groupnameFunc is a shell function printing the name of a group given its name
+// or GID, nothing when it doesn't exist. It reads /etc/group as getent isn't
+// always available.
+const groupnameFunc = `groupname() {
+  while IFS=: read -r n _ g _; do
+    if [ "$n" = "$1" ] || [ "$g" = "$1" ]; then echo "$n"; return; fi
+  done < /etc/group
+}
+`
+
+// userScript returns the shell script creating u in a machine. It uses the
+// shadow utilities (useradd, groupadd, usermod) when the machine has them and
+// falls back to the busybox ones (adduser, addgroup) otherwise.
+func userScript(u *config.User, authorizedKeys []byte) string {
+	var s strings.Builder
+	s.WriteString("set -e\n")
+	s.WriteString(f("name=%s\n", shellQuote(u.Name)))
+	if u.Shell != "" {
+		s.WriteString(f("shell=%s\n", shellQuote(u.Shell)))
+	} else {
+		s.WriteString("shell=/bin/bash\n")
+		s.WriteString("[ -x $shell ] || shell=/bin/sh\n")
+	}
+	s.WriteString(groupnameFunc)
+
+	// User and primary group. The user id is left to the system when it's
+	// already taken, eg. by a user of the image.
+	group, gid := "\"$name\"", ""
+	if u.GID != 0 {
+		group = f("%d", u.GID)
+		gid = f(" -g %d", u.GID)
+	}
+	s.WriteString("uid=\n")
+	if u.UID != 0 {
+		s.WriteString(f("uid='-u %d'\n", u.UID))
+		s.WriteString("while IFS=: read -r _ _ id _; do\n")
+		s.WriteString(f("  if [ \"$id\" = %d ]; then uid=; fi\n", u.UID))
+		s.WriteString("done < /etc/passwd\n")
+	}
+	s.WriteString("if ! id -u \"$name\" >/dev/null 2>&1; then\n")
+	s.WriteString("  if command -v useradd >/dev/null 2>&1; then\n")
+	s.WriteString(f("    [ -n \"$(groupname %s)\" ] || groupadd%s \"$name\"\n", group, gid))
+	s.WriteString(f("    useradd -m -s \"$shell\" $uid -g %s \"$name\"\n", group))
+	s.WriteString("  else\n")
+	s.WriteString(f("    [ -n \"$(groupname %s)\" ] || addgroup%s \"$name\"\n", group, gid))
+	s.WriteString(f("    adduser -D -s \"$shell\" $uid -G \"$(groupname %s)\" \"$name\"\n", group))
+	s.WriteString("  fi\n")
+	s.WriteString("fi\n")
+
+	// Supplementary groups.
+	for _, group := range u.Groups {
+		group = shellQuote(group)
+		s.WriteString("if command -v usermod >/dev/null 2>&1; then\n")
+		s.WriteString(f("  [ -n \"$(groupname %s)\" ] || groupadd %s\n", group, group))
+		s.WriteString(f("  usermod -a -G %s \"$name\"\n", group))
+		s.WriteString("else\n")
+		s.WriteString(f("  [ -n \"$(groupname %s)\" ] || addgroup %s\n", group, group))
+		s.WriteString(f("  addgroup \"$name\" %s\n", group))
+		s.WriteString("fi\n")
+	}
+
+	// Sudo.
+	if u.Sudo {
+		s.WriteString("mkdir -p /etc/sudoers.d\n")
+		s.WriteString("echo \"$name ALL=(ALL) NOPASSWD:ALL\" > \"/etc/sudoers.d/$name\"\n")
+		s.WriteString("chmod 440 \"/etc/sudoers.d/$name\"\n")
+	}
+
+	// SSH keys.
+	s.WriteString("while IFS=: read -r n _ _ _ _ dir _; do\n")
+	s.WriteString("  if [ \"$n\" = \"$name\" ]; then home=$dir; fi\n")
+	s.WriteString("done < /etc/passwd\n")
+	s.WriteString("mkdir -p $home/.ssh; chmod 700 $home/.ssh\n")
+	s.WriteString("cat <<'__EOF' > $home/.ssh/authorized_keys\n")
+	s.Write(authorizedKeys)
+	if len(authorizedKeys) > 0 && authorizedKeys[len(authorizedKeys)-1] != '\n' {
+		s.WriteString("\n")
+	}
+	s.WriteString("__EOF\n")
+	s.WriteString("chmod 600 $home/.ssh/authorized_keys\n")
+	s.WriteString("chown -R \"$name:$(id -g \"$name\")\" $home/.ssh\n")
+
+	return s.String()
+}
+
+// provisionUsers creates the non-root users of m.
+func (c *Cluster) provisionUsers(m *Machine) error {
+	users, err := machineUsers(m)
+	if err != nil {
+		return err
+	}
+	for i := range users {
+		u := &users[i]
+		keys, err := c.userAuthorizedKeys(m, u)
+		if err != nil {
+			return err
+		}
+		m.logger().Infof("Creating user %s in machine %s ...", u.Name, m.name)
+		if err := machineRunShell(m, userScript(u, keys)); err != nil {
+			return errors.Wrapf(err, "create user %s", u.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package cluster
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/weaveworks/footloose/pkg/config"
+)
+
+func TestUserScript(t *testing.T) {
+	u := config.User{
+		Name:   "alice",
+		UID:    1500,
+		GID:    1500,
+		Groups: []string{"docker", "wheel"},
+		Sudo:   true,
+	}
+	script := userScript(&u, []byte("ssh-ed25519 AAAA alice@host"))
+
+	assert.True(t, strings.Contains(script, "name='alice'\n"))
+	assert.True(t, strings.Contains(script, "shell=/bin/bash\n[ -x $shell ] || shell=/bin/sh\n"))
+	assert.True(t, strings.Contains(script, "groupadd -g 1500 \"$name\""))
+	assert.True(t, strings.Contains(script, "useradd -m -s \"$shell\" $uid -g 1500 \"$name\""))
+	assert.True(t, strings.Contains(script, "adduser -D -s \"$shell\" $uid -G \"$(groupname 1500)\" \"$name\""))
+	assert.True(t, strings.Contains(script, "usermod -a -G 'docker' \"$name\""))
+	assert.True(t, strings.Contains(script, "usermod -a -G 'wheel' \"$name\""))
+	assert.True(t, strings.Contains(script, "addgroup \"$name\" 'wheel'"))
+	assert.True(t, strings.Contains(script, "uid='-u 1500'\n"))
+	assert.True(t, strings.Contains(script, "if [ \"$id\" = 1500 ]; then uid=; fi\n"))
+	assert.True(t, strings.Contains(script, "NOPASSWD:ALL"))
+	assert.True(t, strings.Contains(script, "ssh-ed25519 AAAA alice@host\n__EOF\n"))
+
+	u = config.User{Name: "bob", Shell: "/bin/sh"}
+	script = userScript(&u, nil)
+	assert.True(t, strings.Contains(script, "shell='/bin/sh'\n"))
+	assert.True(t, strings.Contains(script, "useradd -m -s \"$shell\" $uid -g \"$name\" \"$name\""))
+	assert.True(t, strings.Contains(script, "adduser -D -s \"$shell\" $uid -G \"$(groupname \"$name\")\" \"$name\""))
+	assert.False(t, strings.Contains(script, "uid='"))
+	assert.False(t, strings.Contains(script, "sudoers"))
+
+	u = config.User{Name: "carol", Shell: "/opt/it's/sh"}
+	script = userScript(&u, nil)
+	assert.True(t, strings.Contains(script, `shell='/opt/it'\''s/sh'`))
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"io/ioutil"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
@@ -41,6 +42,46 @@ type Cluster struct {
 	// This field is optional. If absent, machines are expected to have a public
 	// key defined.
 	PrivateKey string `json:"privateKey,omitempty"`
+
+	// KeyType is the type of the SSH key footloose generates when PrivateKey
+	// doesn't exist yet. One of "rsa", "ecdsa" or "ed25519". Defaults to "rsa".
+	KeyType string `json:"keyType,omitempty"`
+
+	// Hooks are commands run at defined points of the cluster lifecycle.
+	Hooks *Hooks `json:"hooks,omitempty"`
+
+	// TTL is how long the cluster lives, eg. "8h". Once expired, the cluster
+	// is deleted by `footloose reap`. Clusters without TTL are never reaped.
+	TTL string `json:"ttl,omitempty"`
+}
+
+// TTLDuration returns the cluster TTL, 0 when it doesn't have any.
+func (conf Cluster) TTLDuration() time.Duration {
+	d, _ := time.ParseDuration(conf.TTL)
+	return d
+}
+
+// SSH key types.
+const (
+	KeyTypeRSA     = "rsa"
+	KeyTypeECDSA   = "ecdsa"
+	KeyTypeED25519 = "ed25519"
+)
+
+// validate checks basic rules for Cluster's fields
+func (conf Cluster) validate() error {
+	switch conf.KeyType {
+	case "", KeyTypeRSA, KeyTypeECDSA, KeyTypeED25519:
+	default:
+		return fmt.Errorf("unknown key type '%s'", conf.KeyType)
+	}
+	if conf.TTL != "" {
+		d, err := time.ParseDuration(conf.TTL)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("invalid ttl '%s'", conf.TTL)
+		}
+	}
+	return conf.Hooks.validate()
 }
 
 // Config is the top level config object.
@@ -58,6 +99,9 @@ func (conf MachineReplicas) validate() error {
 
 // Validate checks basic rules for Config's fields
 func (conf Config) Validate() error {
+	if err := conf.Cluster.validate(); err != nil {
+		return err
+	}
 	valid := true
 	for _, machine := range conf.Machines {
 		err := machine.validate()
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultHookTimeout is the maximum time a hook can run when it doesn't
+// specify a timeout.
+const DefaultHookTimeout = 5 * time.Minute
+
+// Hook is a shell command run at a given point of the machines lifecycle.
+type Hook struct {
+	// Command is the shell command to run.
+	Command string `json:"command"`
+	// Timeout is the maximum time the command can run, as a duration string,
+	// eg. "30s" or "2m". Defaults to "5m".
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// TimeoutDuration returns the hook timeout.
+func (h Hook) TimeoutDuration() time.Duration {
+	if h.Timeout == "" {
+		return DefaultHookTimeout
+	}
+	d, _ := time.ParseDuration(h.Timeout)
+	return d
+}
+
+// validate checks basic rules for Hook's fields
+func (h Hook) validate() error {
+	if h.Command == "" {
+		return fmt.Errorf("hook: no command given")
+	}
+	if h.Timeout != "" {
+		d, err := time.ParseDuration(h.Timeout)
+		if err != nil {
+			return fmt.Errorf("hook '%s': invalid timeout: %v", h.Command, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("hook '%s': timeout must be positive", h.Command)
+		}
+	}
+	return nil
+}
+
+// HostHooks are hooks run on the host.
+type HostHooks struct {
+	// PreCreate hooks run before creating the machines.
+	PreCreate []Hook `json:"preCreate,omitempty"`
+	// PostCreate hooks run after the machines have been created.
+	PostCreate []Hook `json:"postCreate,omitempty"`
+}
+
+// MachineHooks are hooks run inside machines.
+type MachineHooks struct {
+	// PostCreate hooks run after the machine has been created and provisioned.
+	PostCreate []Hook `json:"postCreate,omitempty"`
+	// PostStart hooks run after a stopped machine has been started.
+	PostStart []Hook `json:"postStart,omitempty"`
+	// PreStop hooks run before stopping the machine.
+	PreStop []Hook `json:"preStop,omitempty"`
+}
+
+// Hooks are commands run at defined points of the machines lifecycle, in the
+// order they are given.
+//
+// Cluster host hooks run once for the whole cluster while machine host hooks
+// run for each machine. Cluster machine hooks run in every machine, before the
+// machine own hooks.
+type Hooks struct {
+	// Host hooks run on the host.
+	Host HostHooks `json:"host,omitempty"`
+	// Machine hooks run inside the machine.
+	Machine MachineHooks `json:"machine,omitempty"`
+}
+
+// validate checks basic rules for Hooks's fields
+func (h *Hooks) validate() error {
+	if h == nil {
+		return nil
+	}
+	for _, hooks := range [][]Hook{
+		h.Host.PreCreate,
+		h.Host.PostCreate,
+		h.Machine.PostCreate,
+		h.Machine.PostStart,
+		h.Machine.PreStop,
+	} {
+		for _, hook := range hooks {
+			if err := hook.validate(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHooksValidate(t *testing.T) {
+	tests := []struct {
+		hooks *Hooks
+		valid bool
+	}{
+		{nil, true},
+		{&Hooks{Host: HostHooks{PreCreate: []Hook{{Command: "true"}}}}, true},
+		{&Hooks{Machine: MachineHooks{PostStart: []Hook{{Command: "true", Timeout: "30s"}}}}, true},
+		{&Hooks{Machine: MachineHooks{PreStop: []Hook{{}}}}, false},
+		{&Hooks{Host: HostHooks{PostCreate: []Hook{{Command: "true", Timeout: "soon"}}}}, false},
+		{&Hooks{Host: HostHooks{PostCreate: []Hook{{Command: "true", Timeout: "-1s"}}}}, false},
+	}
+
+	for _, test := range tests {
+		err := test.hooks.validate()
+		if test.valid {
+			assert.NoError(t, err)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestHookTimeoutDuration(t *testing.T) {
+	assert.Equal(t, DefaultHookTimeout, Hook{Command: "true"}.TimeoutDuration())
+	assert.Equal(t, 30*time.Second, Hook{Command: "true", Timeout: "30s"}.TimeoutDuration())
+}
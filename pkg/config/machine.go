@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"os/user"
+	"regexp"
+	"strconv"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
@@ -39,6 +42,131 @@ type PortMapping struct {
 	ContainerPort uint16 `json:"containerPort"`
 }
 
+// User is a user account created in a Machine.
+type User struct {
+	// Name is the user login name.
+	Name string `json:"name"`
+	// UID is the user id. If 0, or already taken in the machine, the user id is
+	// automatically allocated.
+	UID int `json:"uid,omitempty"`
+	// GID is the id of the user primary group, created with the user name if it
+	// doesn't exist. If 0, a group with the user name is created.
+	GID int `json:"gid,omitempty"`
+	// Groups is the list of supplementary groups the user is a member of.
+	// Groups that don't exist are created.
+	Groups []string `json:"groups,omitempty"`
+	// Sudo gives the user passwordless sudo rights.
+	Sudo bool `json:"sudo,omitempty"`
+	// Shell is the user login shell. Defaults to "/bin/bash", or "/bin/sh" when
+	// the machine doesn't have bash.
+	Shell string `json:"shell,omitempty"`
+	// PublicKeys is the list of public keys, by name, from the footloose key
+	// store to authorize for SSH access.
+	PublicKeys []string `json:"publicKeys,omitempty"`
+	// PublicKeyFiles is the list of public key files on the host to authorize for
+	// SSH access. Can be expanded to user homedir if ~ is found.
+	//
+	// If neither PublicKeys nor PublicKeyFiles are given, the machine root key is
+	// authorized.
+	PublicKeyFiles []string `json:"publicKeyFiles,omitempty"`
+}
+
+var validUserName = regexp.MustCompile(`^[a-z_][a-z0-9_.-]*$`)
+
+// validate checks basic rules for User's fields
+func (u User) validate() error {
+	if !validUserName.MatchString(u.Name) {
+		return fmt.Errorf("invalid user name '%s'", u.Name)
+	}
+	if u.Name == "root" {
+		return fmt.Errorf("user 'root' cannot be declared in the users list")
+	}
+	for _, group := range u.Groups {
+		if !validUserName.MatchString(group) {
+			return fmt.Errorf("user %s: invalid group name '%s'", u.Name, group)
+		}
+	}
+	if strings.ContainsAny(u.Shell, "'\n") {
+		return fmt.Errorf("user %s: invalid shell '%s'", u.Name, u.Shell)
+	}
+	return nil
+}
+
+// HostUser returns the definition of the user running footloose, with sudo
+// rights.
+func HostUser() (User, error) {
+	current, err := user.Current()
+	if err != nil {
+		return User{}, fmt.Errorf("current user: %v", err)
+	}
+	uid, _ := strconv.Atoi(current.Uid)
+	u := User{
+		Name: current.Username,
+		UID:  uid,
+		Sudo: true,
+	}
+	// root already exists in machines, it isn't validated as a user to create.
+	if u.Name != "root" {
+		if err := u.validate(); err != nil {
+			return User{}, fmt.Errorf("host user: %v", err)
+		}
+	}
+	return u, nil
+}
+
+// Readiness check types.
+const (
+	// ReadinessSSH checks sshd answers on the host port mapped to port 22.
+	ReadinessSSH = "ssh"
+	// ReadinessSystemd checks "systemctl is-system-running" reports a system that
+	// has finished booting, running or degraded.
+	ReadinessSystemd = "systemd"
+	// ReadinessTCP checks a TCP connection can be established to the host port
+	// mapped to Port.
+	ReadinessTCP = "tcp"
+	// ReadinessCommand checks Command exits successfully in the machine.
+	ReadinessCommand = "command"
+)
+
+// ReadinessCheck is a check deciding if a machine is ready.
+type ReadinessCheck struct {
+	// Type is the check type. One of "ssh", "systemd", "tcp" or "command".
+	Type string `json:"type"`
+	// Port is the machine port to connect to for "tcp" checks. It needs to be
+	// part of the machine port mappings.
+	Port uint16 `json:"port,omitempty"`
+	// Command is the shell command to run in the machine for "command" checks.
+	Command string `json:"command,omitempty"`
+}
+
+// validate checks basic rules for ReadinessCheck's fields
+func (r ReadinessCheck) validate() error {
+	switch r.Type {
+	case ReadinessSSH, ReadinessSystemd:
+	case ReadinessTCP:
+		if r.Port == 0 {
+			return fmt.Errorf("tcp readiness check: no port given")
+		}
+	case ReadinessCommand:
+		if r.Command == "" {
+			return fmt.Errorf("command readiness check: no command given")
+		}
+	default:
+		return fmt.Errorf("unknown readiness check type '%s'", r.Type)
+	}
+	return nil
+}
+
+// Pull policies, deciding when the image of a machine is pulled.
+const (
+	// PullAlways pulls the image every time machines are created.
+	PullAlways = "Always"
+	// PullIfNotPresent pulls the image when it isn't present locally.
+	PullIfNotPresent = "IfNotPresent"
+	// PullNever never pulls the image, it has to be present locally.
+	PullNever = "Never"
+)
+
 // Machine is the machine configuration.
 type Machine struct {
 	// Name is the machine name.
@@ -52,6 +180,9 @@ type Machine struct {
 	Name string `json:"name"`
 	// Image is the container image to use for this machine.
 	Image string `json:"image"`
+	// PullPolicy decides when Image is pulled: Always, IfNotPresent or Never.
+	// Defaults to IfNotPresent.
+	PullPolicy string `json:"pullPolicy,omitempty"`
 	// Privileged controls whether to start the Machine as a privileged container
 	// or not. Defaults to false.
 	Privileged bool `json:"privileged,omitempty"`
@@ -68,6 +199,24 @@ type Machine struct {
 	// PublicKey is the name of the public key to upload onto the machine for root
 	// SSH access.
 	PublicKey string `json:"publicKey,omitempty"`
+	// Users is the list of non-root users to create in the machine.
+	Users []User `json:"users,omitempty"`
+	// HostUser creates a user named after the user running footloose, with the
+	// same uid, passwordless sudo and the cluster key as authorized key. This
+	// lets "footloose ssh node0" log in without specifying a user.
+	HostUser bool `json:"hostUser,omitempty"`
+
+	// Readiness is the list of checks deciding when the machine is ready to be
+	// used. Defaults to a single "ssh" check when port 22 is mapped.
+	Readiness []ReadinessCheck `json:"readiness,omitempty"`
+
+	// UserData is the path to a cloud-init "#cloud-config" file applied when
+	// the machine is created. footloose interprets the write_files, users,
+	// packages and runcmd modules.
+	UserData string `json:"userData,omitempty"`
+
+	// Hooks are commands run at defined points of the machine lifecycle.
+	Hooks *Hooks `json:"hooks,omitempty"`
 
 	// Backend specifies the runtime backend for this machine
 	Backend string `json:"backend,omitempty"`
@@ -110,11 +259,39 @@ type Ignite struct {
 }
 
 // validate checks basic rules for Machine's fields
+// mapsPort returns whether port is mapped to a host port.
+func (conf Machine) mapsPort(port uint16) bool {
+	for _, mapping := range conf.PortMappings {
+		if mapping.ContainerPort == port {
+			return true
+		}
+	}
+	return false
+}
+
 func (conf Machine) validate() error {
 	validName := strings.Contains(conf.Name, "%d")
 	if !validName {
 		log.Warnf("Machine conf validation: machine name %v is not valid, it should contains %%d", conf.Name)
 		return fmt.Errorf("Machine configuration not valid")
 	}
-	return nil
+	switch conf.PullPolicy {
+	case "", PullAlways, PullIfNotPresent, PullNever:
+	default:
+		return fmt.Errorf("unknown pull policy '%s', expected %s, %s or %s", conf.PullPolicy, PullAlways, PullIfNotPresent, PullNever)
+	}
+	for _, user := range conf.Users {
+		if err := user.validate(); err != nil {
+			return err
+		}
+	}
+	for _, check := range conf.Readiness {
+		if err := check.validate(); err != nil {
+			return err
+		}
+		if check.Type == ReadinessTCP && !conf.mapsPort(check.Port) {
+			return fmt.Errorf("tcp readiness check: port %d isn't part of the machine port mappings", check.Port)
+		}
+	}
+	return conf.Hooks.validate()
 }
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserValidate(t *testing.T) {
+	tests := []struct {
+		user  User
+		valid bool
+	}{
+		{User{Name: "alice", Groups: []string{"wheel"}}, true},
+		{User{Name: "root"}, false},
+		{User{Name: "Alice"}, false},
+		{User{Name: "alice", Groups: []string{"bad group"}}, false},
+		{User{Name: "alice", Shell: "/bin/sh'; rm -rf /"}, false},
+	}
+
+	for _, test := range tests {
+		err := test.user.validate()
+		if test.valid {
+			assert.NoError(t, err)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestReadinessCheckValidate(t *testing.T) {
+	tests := []struct {
+		check ReadinessCheck
+		valid bool
+	}{
+		{ReadinessCheck{Type: ReadinessSSH}, true},
+		{ReadinessCheck{Type: ReadinessSystemd}, true},
+		{ReadinessCheck{Type: ReadinessTCP, Port: 80}, true},
+		{ReadinessCheck{Type: ReadinessTCP}, false},
+		{ReadinessCheck{Type: ReadinessCommand, Command: "test -f /ready"}, true},
+		{ReadinessCheck{Type: ReadinessCommand}, false},
+		{ReadinessCheck{Type: "http"}, false},
+	}
+
+	for _, test := range tests {
+		err := test.check.validate()
+		if test.valid {
+			assert.NoError(t, err)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
+
+func TestMachineReadinessValidate(t *testing.T) {
+	check := ReadinessCheck{Type: ReadinessTCP, Port: 80}
+	machine := Machine{Name: "node%d", Readiness: []ReadinessCheck{check}}
+	assert.Error(t, machine.validate())
+
+	machine.PortMappings = []PortMapping{{ContainerPort: 22}, {ContainerPort: 80}}
+	assert.NoError(t, machine.validate())
+}
+
+func TestMachinePullPolicyValidate(t *testing.T) {
+	tests := []struct {
+		policy string
+		valid  bool
+	}{
+		{"", true},
+		{PullAlways, true},
+		{PullIfNotPresent, true},
+		{PullNever, true},
+		{"never", false},
+	}
+
+	for _, test := range tests {
+		err := Machine{Name: "node%d", PullPolicy: test.policy}.validate()
+		if test.valid {
+			assert.NoError(t, err)
+		} else {
+			assert.Error(t, err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// CloudConfigUser is a user declared in the "users" module of cloud-config
+// user data.
+type CloudConfigUser struct {
+	User
+	// SudoRules are the sudoers rules of the user, without the user name, eg.
+	// "ALL=(ALL) NOPASSWD:ALL".
+	SudoRules []string
+	// AuthorizedKeys are the public keys authorized for SSH access.
+	AuthorizedKeys []string
+}
+
+type cloudUser struct {
+	Name              string      `json:"name"`
+	Groups            interface{} `json:"groups"`
+	Sudo              interface{} `json:"sudo"`
+	Shell             string      `json:"shell"`
+	UID               interface{} `json:"uid"`
+	SSHAuthorizedKeys []string    `json:"ssh_authorized_keys"`
+}
+
+// stringList converts a cloud-config value that is either a comma separated
+// string or a list of strings.
+func stringList(v interface{}) []string {
+	var list []string
+	switch v := v.(type) {
+	case string:
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			list = append(list, fmt.Sprint(item))
+		}
+	}
+	return list
+}
+
+// CloudConfigUsers converts and validates the entries of the "users" module
+// of cloud-config user data. The "default" user is ignored.
+func CloudConfigUsers(entries []interface{}) ([]CloudConfigUser, error) {
+	var users []CloudConfigUser
+	for _, entry := range entries {
+		if name, ok := entry.(string); ok {
+			if name == "default" {
+				continue
+			}
+			entry = map[string]interface{}{"name": name}
+		}
+		data, err := yaml.Marshal(entry)
+		if err != nil {
+			return nil, err
+		}
+		cu := cloudUser{}
+		if err := yaml.Unmarshal(data, &cu); err != nil {
+			return nil, fmt.Errorf("users: %v", err)
+		}
+
+		u := CloudConfigUser{
+			User: User{
+				Name:   cu.Name,
+				Groups: stringList(cu.Groups),
+				Shell:  cu.Shell,
+			},
+			AuthorizedKeys: cu.SSHAuthorizedKeys,
+		}
+		if cu.UID != nil {
+			uid, err := strconv.Atoi(fmt.Sprint(cu.UID))
+			if err != nil {
+				return nil, fmt.Errorf("users: %s: invalid uid '%v'", cu.Name, cu.UID)
+			}
+			u.UID = uid
+		}
+		switch sudo := cu.Sudo.(type) {
+		case string:
+			u.SudoRules = []string{sudo}
+		case []interface{}:
+			u.SudoRules = stringList(sudo)
+		}
+		if err := u.validate(); err != nil {
+			return nil, fmt.Errorf("users: %v", err)
+		}
+		users = append(users, u)
+	}
+	return users, nil
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/stretchr/testify/assert"
+)
+
+func cloudConfigUsers(t *testing.T, data string) ([]CloudConfigUser, error) {
+	var entries []interface{}
+	assert.NoError(t, yaml.Unmarshal([]byte(data), &entries))
+	return CloudConfigUsers(entries)
+}
+
+func TestCloudConfigUsers(t *testing.T) {
+	users, err := cloudConfigUsers(t, `
+- default
+- name: alice
+  groups: [adm, wheel]
+  sudo: ALL=(ALL) NOPASSWD:ALL
+  uid: 1500
+  ssh_authorized_keys:
+  - ssh-ed25519 AAAA alice@laptop
+- bob
+`)
+	assert.NoError(t, err)
+	assert.Len(t, users, 2)
+	assert.Equal(t, "alice", users[0].Name)
+	assert.Equal(t, 1500, users[0].UID)
+	assert.Equal(t, []string{"adm", "wheel"}, users[0].Groups)
+	assert.Equal(t, []string{"ALL=(ALL) NOPASSWD:ALL"}, users[0].SudoRules)
+	assert.Equal(t, []string{"ssh-ed25519 AAAA alice@laptop"}, users[0].AuthorizedKeys)
+	assert.Equal(t, "bob", users[1].Name)
+
+	for _, invalid := range []string{
+		"- name: root",
+		"- name: 'alice; rm -rf /'",
+		"- name: alice\n  groups: 'adm, $(id)'",
+		"- name: alice\n  uid: abc",
+	} {
+		_, err := cloudConfigUsers(t, invalid)
+		assert.Error(t, err, invalid)
+	}
+}
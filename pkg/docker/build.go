@@ -0,0 +1,41 @@
+package docker
+
+import (
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/weaveworks/footloose/pkg/exec"
+)
+
+// Build builds an image tagged tag from dockerfile, as in
+// `docker build --tag tag -`. The build has no context: the Dockerfile can't
+// COPY or ADD files.
+func Build(tag string, dockerfile io.Reader) error {
+	cmd := exec.Command("docker", "build", "--tag", tag, "-")
+	cmd.SetStdin(dockerfile)
+	cmd.SetStdout(os.Stdout)
+	cmd.SetStderr(os.Stderr)
+	return cmd.Run()
+}
+
+// ImageExists returns whether image is present locally.
+func ImageExists(image string) bool {
+	cmd := exec.Command("docker", "inspect", "--type=image", image)
+	return cmd.Run() == nil
+}
+
+// ImageID returns the ID of image, which has to be present locally.
+func ImageID(image string) (string, error) {
+	cmd := exec.Command("docker", "inspect", "--type=image", "--format", "{{.Id}}", image)
+	lines, err := exec.CombinedOutputLines(cmd)
+	if err != nil {
+		return "", errors.Wrapf(err, "image %s", image)
+	}
+	if len(lines) != 1 {
+		return "", errors.Errorf("image %s: unexpected docker inspect output: %s", image, strings.Join(lines, "\n"))
+	}
+	return lines[0], nil
+}
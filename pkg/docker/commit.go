@@ -0,0 +1,11 @@
+package docker
+
+import (
+	"github.com/weaveworks/footloose/pkg/exec"
+)
+
+// Commit creates image from the filesystem of container, as in
+// `docker commit`.
+func Commit(container, image string) error {
+	return exec.Command("docker", "commit", container, image).Run()
+}
@@ -0,0 +1,52 @@
+package docker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	osexec "os/exec"
+
+	"github.com/pkg/errors"
+)
+
+// Event is an event reported by `docker events`.
+type Event struct {
+	Type   string `json:"Type"`
+	Action string `json:"Action"`
+	Actor  struct {
+		ID         string            `json:"ID"`
+		Attributes map[string]string `json:"Attributes"`
+	} `json:"Actor"`
+	TimeNano int64 `json:"timeNano"`
+}
+
+// Events streams the events matching filters to handle, as in
+// `docker events --filter`, until ctx is done.
+func Events(ctx context.Context, handle func(*Event), filters ...string) error {
+	args := []string{"events", "--format", "{{json .}}"}
+	for _, filter := range filters {
+		args = append(args, "--filter", filter)
+	}
+	// Not using pkg/exec: the command needs to be stopped with ctx.
+	cmd := osexec.CommandContext(ctx, "docker", args...)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		event := Event{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		handle(&event)
+	}
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return nil
+	}
+	return errors.Wrap(err, "docker events")
+}
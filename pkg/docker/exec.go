@@ -17,6 +17,7 @@ limitations under the License.
 package docker
 
 import (
+	"context"
 	"io"
 
 	"github.com/weaveworks/footloose/pkg/exec"
@@ -53,7 +54,8 @@ type containerCmd struct {
 	stderr   io.Writer
 }
 
-func (c *containerCmd) Run() error {
+// hostCmd returns the local command running c.
+func (c *containerCmd) hostCmd() exec.Cmd {
 	args := []string{
 		"exec",
 		// run with priviliges so we can remount etc..
@@ -66,7 +68,9 @@ func (c *containerCmd) Run() error {
 			"-i", // interactive so we can supply input
 		)
 	}
-	if c.stderr != nil || c.stdout != nil {
+	// a tty would mangle piped input, and docker refuses to allocate one
+	// when stdin isn't a terminal
+	if c.stdin == nil && (c.stderr != nil || c.stdout != nil) {
 		args = append(args,
 			"-t", // use a tty so we can get output
 		)
@@ -97,7 +101,16 @@ func (c *containerCmd) Run() error {
 	if c.stdout != nil {
 		cmd.SetStdout(c.stdout)
 	}
-	return cmd.Run()
+	return cmd
+}
+
+func (c *containerCmd) Run() error {
+	return c.hostCmd().Run()
+}
+
+// RunContext runs c, killing the local client process when ctx is done.
+func (c *containerCmd) RunContext(ctx context.Context) error {
+	return exec.RunContext(ctx, c.hostCmd())
 }
 
 func (c *containerCmd) SetEnv(env ...string) {
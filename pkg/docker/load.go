@@ -0,0 +1,10 @@
+package docker
+
+import (
+	"github.com/weaveworks/footloose/pkg/exec"
+)
+
+// Load loads the images of archive, as in `docker load`.
+func Load(archive string) error {
+	return exec.Command("docker", "load", "-i", archive).Run()
+}
@@ -0,0 +1,25 @@
+package docker
+
+import (
+	"io"
+
+	"github.com/weaveworks/footloose/pkg/exec"
+)
+
+// Logs writes the output of container to stdout and stderr, as in
+// `docker logs`. since, if not empty, only shows the output since a timestamp
+// or a relative duration, eg. "10m".
+func Logs(container string, follow bool, since string, stdout, stderr io.Writer) error {
+	args := []string{"logs"}
+	if follow {
+		args = append(args, "--follow")
+	}
+	if since != "" {
+		args = append(args, "--since", since)
+	}
+	args = append(args, container)
+	cmd := exec.Command("docker", args...)
+	cmd.SetStdout(stdout)
+	cmd.SetStderr(stderr)
+	return cmd.Run()
+}
@@ -0,0 +1,27 @@
+package docker
+
+import (
+	"strings"
+
+	"github.com/weaveworks/footloose/pkg/exec"
+)
+
+// List returns the containers, running or not, matching all the given filters
+// (as in "docker ps --filter"), one line per container formatted with format.
+func List(format string, filters ...string) ([]string, error) {
+	args := []string{"ps", "-a", "--no-trunc", "--format", format}
+	for _, filter := range filters {
+		args = append(args, "--filter", filter)
+	}
+	lines, err := exec.CombinedOutputLines(exec.Command("docker", args...))
+	if err != nil {
+		return nil, err
+	}
+	var res []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			res = append(res, line)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,45 @@
+package docker
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/weaveworks/footloose/pkg/exec"
+)
+
+// ContainerStats are the resource usage statistics of a container, as
+// formatted by `docker stats`.
+type ContainerStats struct {
+	// Container is the container name or ID, as given to Stats.
+	Container string `json:"Container"`
+	Name      string `json:"Name"`
+	CPUPerc   string `json:"CPUPerc"`
+	MemUsage  string `json:"MemUsage"`
+	NetIO     string `json:"NetIO"`
+	BlockIO   string `json:"BlockIO"`
+	PIDs      string `json:"PIDs"`
+}
+
+// Stats returns the resource usage statistics of containers, as in
+// `docker stats --no-stream`.
+func Stats(containers ...string) ([]ContainerStats, error) {
+	args := append([]string{"stats", "--no-stream", "--format", "{{json .}}"}, containers...)
+	cmd := exec.Command("docker", args...)
+	lines, err := exec.CombinedOutputLines(cmd)
+	if err != nil {
+		if len(lines) > 0 {
+			return nil, errors.Wrap(err, lines[0])
+		}
+		return nil, err
+	}
+	var stats []ContainerStats
+	for _, line := range lines {
+		s := ContainerStats{}
+		if err := json.Unmarshal([]byte(line), &s); err != nil {
+			return nil, errors.Wrapf(err, "docker stats: invalid output '%s'", line)
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
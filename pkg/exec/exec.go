@@ -22,6 +22,7 @@ package exec
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"io"
 	"os"
 
@@ -38,6 +39,23 @@ type Cmd interface {
 	SetStderr(io.Writer)
 }
 
+// ContextRunner is implemented by commands that can be killed before they
+// complete.
+type ContextRunner interface {
+	// RunContext runs the command, killing the local process when ctx is done
+	// and waiting for it to exit.
+	RunContext(ctx context.Context) error
+}
+
+// RunContext runs cmd, killing it when ctx is done if it implements
+// ContextRunner. Other commands run to completion.
+func RunContext(ctx context.Context, cmd Cmd) error {
+	if runner, ok := cmd.(ContextRunner); ok {
+		return runner.RunContext(ctx)
+	}
+	return cmd.Run()
+}
+
 // Cmder abstracts over creating commands
 type Cmder interface {
 	// command, args..., just like os/exec.Cmd
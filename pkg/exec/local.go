@@ -17,6 +17,7 @@ limitations under the License.
 package exec
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -72,6 +73,26 @@ func (cmd *LocalCmd) Run() error {
 	return cmd.Cmd.Run()
 }
 
+// RunContext runs, killing the process when ctx is done
+func (cmd *LocalCmd) RunContext(ctx context.Context) error {
+	log.Debugf("Running: %v %v", cmd.Path, cmd.Args)
+	if err := cmd.Cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Cmd.Wait()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		_ = cmd.Process.Kill()
+		<-done
+		return ctx.Err()
+	}
+}
+
 func ExecuteCommand(command string, args ...string) (string, error) {
 	cmd := osexec.Command(command, args...)
 	out, err := cmd.CombinedOutput()
@@ -0,0 +1,16 @@
+package ignite
+
+import (
+	"github.com/weaveworks/footloose/pkg/exec"
+)
+
+// CopyTo copies the file or directory at hostPath to the VM at destPath
+func CopyTo(hostPath, name, destPath string) error {
+	return exec.CommandWithLogging(execName, "cp", hostPath, name+":"+destPath)
+}
+
+// CopyFrom copies the file or directory in the VM at srcPath to the host at
+// hostPath
+func CopyFrom(name, srcPath, hostPath string) error {
+	return exec.CommandWithLogging(execName, "cp", name+":"+srcPath, hostPath)
+}
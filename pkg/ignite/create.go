@@ -13,12 +13,12 @@ const (
 	BackendName = "ignite"
 )
 
-// This offset is incremented for each port so we avoid
-// duplicate port bindings (and hopefully port collisions)
-var portOffset uint16
-
 // Create creates an Ignite VM using "ignite run", it doesn't return a container ID
-func Create(name string, spec *config.Machine, pubKeyPath string) (id string, err error) {
+//
+// i is the machine index in the cluster. It's used as an offset to the
+// configured host ports so we avoid duplicate port bindings (and hopefully port
+// collisions).
+func Create(name string, spec *config.Machine, pubKeyPath string, i int) (id string, err error) {
 	runArgs := []string{
 		"run",
 		spec.Image,
@@ -43,15 +43,12 @@ func Create(name string, spec *config.Machine, pubKeyPath string) (id string, er
 			}
 		} else {
 			// If defined, apply an offset so all VMs won't use the same port
-			mapping.HostPort += portOffset
+			mapping.HostPort += uint16(i)
 		}
 
 		runArgs = append(runArgs, fmt.Sprintf("--ports=%d:%d", int(mapping.HostPort), mapping.ContainerPort))
 	}
 
-	// Increment portOffset per-machine
-	portOffset++
-
 	_, err = exec.ExecuteCommand(execName, runArgs...)
 	return "", err
 }
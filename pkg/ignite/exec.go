@@ -0,0 +1,105 @@
+package ignite
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/weaveworks/footloose/pkg/exec"
+)
+
+// vmCmder implements exec.Cmder for Ignite VMs
+type vmCmder struct {
+	name string
+}
+
+// VMCmder creates a new exec.Cmder against an Ignite VM
+func VMCmder(name string) exec.Cmder {
+	return &vmCmder{
+		name: name,
+	}
+}
+
+func (c *vmCmder) Command(command string, args ...string) exec.Cmd {
+	return &vmCmd{
+		name:    c.name,
+		command: command,
+		args:    args,
+	}
+}
+
+// vmCmd implements exec.Cmd for Ignite VMs
+type vmCmd struct {
+	name    string // the VM name
+	command string
+	args    []string
+	env     []string
+	stdin   io.Reader
+	stdout  io.Writer
+	stderr  io.Writer
+}
+
+// shellQuote quotes s for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// execArgs returns the arguments of ignite exec running c. ignite exec joins
+// its arguments with spaces into the command line run through SSH, so each of
+// them is quoted to reach the VM as a single word.
+func (c *vmCmd) execArgs() []string {
+	args := []string{"exec", c.name}
+	// ignite exec runs the command through SSH, there's no way to pass the
+	// environment other than prefixing the command with it.
+	if len(c.env) > 0 {
+		args = append(args, "env")
+		for _, env := range c.env {
+			args = append(args, shellQuote(env))
+		}
+	}
+	args = append(args, shellQuote(c.command))
+	for _, arg := range c.args {
+		args = append(args, shellQuote(arg))
+	}
+	return args
+}
+
+// hostCmd returns the local command running c.
+func (c *vmCmd) hostCmd() exec.Cmd {
+	cmd := exec.Command(execName, c.execArgs()...)
+	if c.stdin != nil {
+		cmd.SetStdin(c.stdin)
+	}
+	if c.stderr != nil {
+		cmd.SetStderr(c.stderr)
+	}
+	if c.stdout != nil {
+		cmd.SetStdout(c.stdout)
+	}
+	return cmd
+}
+
+func (c *vmCmd) Run() error {
+	return c.hostCmd().Run()
+}
+
+// RunContext runs c, killing the local client process when ctx is done.
+func (c *vmCmd) RunContext(ctx context.Context) error {
+	return exec.RunContext(ctx, c.hostCmd())
+}
+
+func (c *vmCmd) SetEnv(env ...string) {
+	c.env = env
+}
+
+func (c *vmCmd) SetStdin(r io.Reader) {
+	c.stdin = r
+}
+
+func (c *vmCmd) SetStdout(w io.Writer) {
+	c.stdout = w
+}
+
+func (c *vmCmd) SetStderr(w io.Writer) {
+	c.stderr = w
+}
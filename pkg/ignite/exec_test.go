@@ -0,0 +1,25 @@
+package ignite
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecArgs(t *testing.T) {
+	cmd := VMCmder("node0").Command("/bin/sh", "-c", `echo "$GREETING, it's $0"`, "sh")
+	cmd.SetEnv("GREETING=hello world")
+	args := cmd.(*vmCmd).execArgs()
+	assert.Equal(t, []string{
+		"exec", "node0",
+		"env", `'GREETING=hello world'`,
+		`'/bin/sh'`, `'-c'`, `'echo "$GREETING, it'\''s $0"'`, `'sh'`,
+	}, args)
+
+	// The command line ignite exec runs through SSH is the joined arguments.
+	out, err := exec.Command("/bin/sh", "-c", strings.Join(args[2:], " ")).Output()
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world, it's sh", strings.TrimSpace(string(out)))
+}
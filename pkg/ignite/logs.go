@@ -0,0 +1,16 @@
+package ignite
+
+import (
+	"io"
+
+	"github.com/weaveworks/footloose/pkg/exec"
+)
+
+// Logs writes the console output of a VM to stdout and stderr, as in
+// `ignite logs`.
+func Logs(name string, stdout, stderr io.Writer) error {
+	cmd := exec.Command(execName, "logs", name)
+	cmd.SetStdout(stdout)
+	cmd.SetStderr(stderr)
+	return cmd.Run()
+}
@@ -0,0 +1,237 @@
+package image
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+
+	"github.com/weaveworks/footloose/pkg/docker"
+	"github.com/weaveworks/footloose/pkg/exec"
+)
+
+// CheckResult is the result of checking an image for a requirement of
+// footloose machines.
+type CheckResult struct {
+	// Requirement is what was checked.
+	Requirement string `json:"requirement"`
+	// OK is true when the image meets the requirement.
+	OK bool `json:"ok"`
+	// Required is true when machines can't work without the requirement.
+	// Other requirements only trigger warnings.
+	Required bool `json:"required"`
+	// Detail is what was found.
+	Detail string `json:"detail,omitempty"`
+	// Hint explains how to fix the image when the requirement isn't met.
+	Hint string `json:"hint,omitempty"`
+}
+
+// CheckReport is the result of checking an image.
+type CheckReport struct {
+	Image   string        `json:"image"`
+	Results []CheckResult `json:"results"`
+}
+
+// OK returns whether the image meets all the required requirements.
+func (r *CheckReport) OK() bool {
+	return len(r.Failed()) == 0
+}
+
+// Failed returns the required requirements the image doesn't meet.
+func (r *CheckReport) Failed() []CheckResult {
+	var failed []CheckResult
+	for _, result := range r.Results {
+		if result.Required && !result.OK {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Format writes the report as a table, followed by the hints to fix the image.
+func (r *CheckReport) Format(w io.Writer) error {
+	table := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(table, "REQUIREMENT\tSTATUS\tDETAIL")
+	var hints []string
+	for _, result := range r.Results {
+		status := "ok"
+		if !result.OK {
+			status = "warning"
+			if result.Required {
+				status = "FAILED"
+			}
+			hints = append(hints, result.Requirement+": "+result.Hint)
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\n", result.Requirement, status, result.Detail)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	if len(hints) > 0 {
+		fmt.Fprintln(w, "\nHints:")
+		for _, hint := range hints {
+			fmt.Fprintln(w, "  "+hint)
+		}
+	}
+	return nil
+}
+
+// checkScript probes the image for the requirements. It only relies on a POSIX
+// shell and prints key=value lines.
+const checkScript = `
+[ -x /bin/bash ] && echo bash=yes || echo bash=no
+sshd=$(command -v sshd 2>/dev/null)
+for path in /usr/sbin/sshd /usr/bin/sshd /sbin/sshd; do
+  [ -z "$sshd" ] && [ -x "$path" ] && sshd=$path
+done
+echo "sshd=$sshd"
+keys=0
+for key in /etc/ssh/ssh_host_*_key; do
+  [ -e "$key" ] && keys=$((keys+1))
+done
+echo "hostkeys=$keys"
+command -v ssh-keygen >/dev/null 2>&1 && echo keygen=yes || echo keygen=no
+if [ -x /lib/systemd/systemd ] || [ -x /usr/lib/systemd/systemd ]; then
+  echo init=systemd
+elif [ -x /sbin/openrc ] || [ -x /sbin/openrc-init ]; then
+  echo init=openrc
+elif [ -x /sbin/init ]; then
+  echo init=other
+else
+  echo init=none
+fi
+run=0
+for f in /run/* /run/.[!.]*; do
+  [ -e "$f" ] && run=$((run+1))
+done
+echo "run=$run"
+if [ -L /var/run ]; then echo varrun=link; elif [ -d /var/run ]; then echo varrun=dir; else echo varrun=none; fi
+`
+
+// missingShell returns whether output, from a failed docker run of /bin/sh,
+// says the image doesn't have /bin/sh.
+func missingShell(output string) bool {
+	return strings.Contains(output, "exec: ") && strings.Contains(output, "/bin/sh") &&
+		(strings.Contains(output, "no such file or directory") || strings.Contains(output, "not found"))
+}
+
+// probe runs checkScript in a throwaway container. It returns nil values, and
+// no error, when the image doesn't have /bin/sh.
+func probe(image string) (map[string]string, error) {
+	if !docker.ImageExists(image) {
+		return nil, errors.Errorf("image %s isn't present locally", image)
+	}
+	cmd := exec.Command("docker", "run", "--rm", "--entrypoint", "/bin/sh", image, "-c", checkScript)
+	lines, err := exec.CombinedOutputLines(cmd)
+	values := make(map[string]string)
+	for _, line := range lines {
+		if i := strings.Index(line, "="); i > 0 {
+			values[line[:i]] = line[i+1:]
+		}
+	}
+	if _, ok := values["bash"]; ok {
+		return values, nil
+	}
+	output := strings.Join(lines, "\n")
+	if err != nil && missingShell(output) {
+		return nil, nil
+	}
+	if err == nil {
+		err = errors.New("unexpected output")
+	}
+	return nil, errors.Wrapf(err, "checking %s: %s", image, output)
+}
+
+// usesInit returns whether cmd, the command of a machine, is an init system.
+func usesInit(cmd string) bool {
+	return cmd == "" || strings.HasPrefix(cmd, "/sbin/init")
+}
+
+// Check starts a throwaway container of image and reports whether it meets the
+// requirements of footloose machines running cmd, the default command when
+// empty. The image has to be present locally.
+func Check(image, cmd string) (*CheckReport, error) {
+	values, err := probe(image)
+	if err != nil {
+		return nil, err
+	}
+	return newCheckReport(image, cmd, values), nil
+}
+
+// newCheckReport interprets the values found by probe.
+func newCheckReport(image, cmd string, values map[string]string) *CheckReport {
+	report := &CheckReport{Image: image}
+	if values == nil {
+		report.Results = []CheckResult{{
+			Requirement: "/bin/sh",
+			Required:    true,
+			Detail:      "missing",
+			Hint:        "footloose provisions machines with shell scripts, add a shell to the image",
+		}}
+		return report
+	}
+	add := func(result CheckResult) {
+		report.Results = append(report.Results, result)
+	}
+
+	bash := CheckResult{Requirement: "/bin/bash", OK: values["bash"] == "yes", Detail: "present"}
+	if !bash.OK {
+		bash.Detail = "missing, users get /bin/sh as their shell"
+		bash.Hint = "install the bash package, or give users another shell"
+	}
+	add(bash)
+
+	sshd := CheckResult{Requirement: "sshd", Required: true, Detail: values["sshd"]}
+	sshd.OK = sshd.Detail != ""
+	if !sshd.OK {
+		sshd.Detail = "missing"
+		sshd.Hint = "install the OpenSSH server, eg. the openssh-server package"
+	}
+	add(sshd)
+
+	keys, _ := strconv.Atoi(values["hostkeys"])
+	hostKeys := CheckResult{Requirement: "SSH host keys", OK: keys > 0, Detail: fmt.Sprintf("%d present", keys)}
+	if !hostKeys.OK {
+		if values["keygen"] == "yes" {
+			hostKeys.Detail = "missing, ssh-keygen is available to generate them"
+		} else {
+			hostKeys.Detail = "missing, ssh-keygen isn't available"
+		}
+		hostKeys.Hint = "run ssh-keygen -A when building the image, unless the init system generates them at boot"
+		// footloose generates missing host keys with ssh-keygen.
+		hostKeys.Required = values["keygen"] != "yes"
+	}
+	add(hostKeys)
+
+	initResult := CheckResult{Requirement: "init system", Required: usesInit(cmd), Detail: values["init"]}
+	switch values["init"] {
+	case "systemd", "openrc":
+		initResult.OK = true
+	case "other":
+		initResult.OK = true
+		initResult.Detail = "/sbin/init, not systemd or OpenRC"
+	default:
+		initResult.Detail = "missing"
+		initResult.Hint = "install systemd or OpenRC, or give the machine a cmd starting sshd, eg. sshd -D"
+	}
+	if !initResult.Required && !initResult.OK {
+		initResult.Detail += fmt.Sprintf(", not needed to run %s", cmd)
+	}
+	add(initResult)
+
+	run, _ := strconv.Atoi(values["run"])
+	runResult := CheckResult{Requirement: "tmpfs /run", OK: run == 0 && values["varrun"] != "dir", Detail: "empty"}
+	if run > 0 {
+		runResult.Detail = fmt.Sprintf("%d entries hidden by the tmpfs footloose mounts on /run", run)
+		runResult.Hint = "create what's needed in /run at boot, eg. with systemd-tmpfiles"
+	} else if values["varrun"] == "dir" {
+		runResult.Detail = "/var/run is a directory, not a link to /run"
+		runResult.Hint = "replace /var/run with a link to /run"
+	}
+	add(runResult)
+
+	return report
+}
@@ -0,0 +1,92 @@
+package image
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func results(report *CheckReport) map[string]CheckResult {
+	byRequirement := make(map[string]CheckResult)
+	for _, result := range report.Results {
+		byRequirement[result.Requirement] = result
+	}
+	return byRequirement
+}
+
+func TestCheckReport(t *testing.T) {
+	// A footloose image.
+	report := newCheckReport("centos7", "", map[string]string{
+		"bash": "yes", "sshd": "/usr/sbin/sshd", "hostkeys": "0", "keygen": "yes",
+		"init": "systemd", "run": "0", "varrun": "link",
+	})
+	assert.True(t, report.OK())
+	assert.False(t, results(report)["SSH host keys"].OK)
+
+	// Alpine, without bash nor sshd.
+	report = newCheckReport("alpine", "", map[string]string{
+		"bash": "no", "sshd": "", "hostkeys": "0", "keygen": "no",
+		"init": "none", "run": "0", "varrun": "link",
+	})
+	assert.False(t, report.OK())
+	var failed []string
+	for _, result := range report.Failed() {
+		failed = append(failed, result.Requirement)
+	}
+	assert.Equal(t, []string{"sshd", "SSH host keys", "init system"}, failed)
+
+	// Alpine with OpenRC and OpenSSH only needs bash for users' shells.
+	report = newCheckReport("alpine", "", map[string]string{
+		"bash": "no", "sshd": "/usr/sbin/sshd", "hostkeys": "0", "keygen": "yes",
+		"init": "openrc", "run": "0", "varrun": "link",
+	})
+	assert.True(t, report.OK())
+	assert.False(t, results(report)["/bin/bash"].OK)
+
+	// No init system is needed to run sshd directly.
+	report = newCheckReport("sshd", "/usr/sbin/sshd -D", map[string]string{
+		"bash": "yes", "sshd": "/usr/sbin/sshd", "hostkeys": "1", "keygen": "yes",
+		"init": "none", "run": "2", "varrun": "dir",
+	})
+	assert.True(t, report.OK())
+	assert.False(t, results(report)["tmpfs /run"].OK)
+
+	// No shell at all.
+	report = newCheckReport("distroless", "", nil)
+	assert.False(t, report.OK())
+	assert.Equal(t, "/bin/sh", report.Failed()[0].Requirement)
+}
+
+func TestCheckReportFormat(t *testing.T) {
+	report := newCheckReport("alpine", "", map[string]string{
+		"bash": "yes", "sshd": "", "hostkeys": "1", "keygen": "yes",
+		"init": "openrc", "run": "0", "varrun": "link",
+	})
+	var out bytes.Buffer
+	assert.NoError(t, report.Format(&out))
+	assert.Equal(t, `REQUIREMENT     STATUS   DETAIL
+/bin/bash       ok       present
+sshd            FAILED   missing
+SSH host keys   ok       1 present
+init system     ok       openrc
+tmpfs /run      ok       empty
+
+Hints:
+  sshd: install the OpenSSH server, eg. the openssh-server package
+`, out.String())
+}
+
+func TestMissingShell(t *testing.T) {
+	assert.True(t, missingShell(`docker: Error response from daemon: OCI runtime create failed: container_linux.go:345: starting container process caused "exec: \"/bin/sh\": stat /bin/sh: no such file or directory": unknown.`))
+	assert.True(t, missingShell(`docker: Error response from daemon: failed to create shim task: OCI runtime create failed: runc create failed: unable to start container process: exec: "/bin/sh": stat /bin/sh: no such file or directory: unknown.`))
+	assert.False(t, missingShell(`docker: Error response from daemon: manifest for alpine:3.99 not found: manifest unknown: manifest unknown.`))
+	assert.False(t, missingShell(`docker: Error response from daemon: pull access denied for private/image, repository does not exist or may require 'docker login'.`))
+}
+
+func TestIsFootlooseImage(t *testing.T) {
+	assert.True(t, IsFootlooseImage("quay.io/footloose/centos7"))
+	assert.True(t, IsFootlooseImage("quay.io/footloose/ubuntu18.04:0.6.3"))
+	assert.False(t, IsFootlooseImage("quay.io/footloose/unknown"))
+	assert.False(t, IsFootlooseImage("alpine:3.10"))
+}
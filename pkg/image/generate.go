@@ -0,0 +1,52 @@
+//go:build ignore
+// +build ignore
+
+// generate embeds the Dockerfiles of the images/ directory in
+// dockerfiles_generated.go.
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	paths, err := filepath.Glob("../../images/*/Dockerfile")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by generate.go. DO NOT EDIT.\n\n")
+	buf.WriteString("package image\n\n")
+	buf.WriteString("// dockerfiles are the Dockerfiles of the images/ directory, by distro.\n")
+	buf.WriteString("var dockerfiles = map[string]string{\n")
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		distro := filepath.Base(filepath.Dir(path))
+		content := string(data)
+		if strings.Contains(content, "`") {
+			content = strconv.Quote(content)
+		} else {
+			content = "`" + content + "`"
+		}
+		buf.WriteString(strconv.Quote(distro) + ": " + content + ",\n")
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile("dockerfiles_generated.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,87 @@
+// Package image builds the machine images footloose provides, checks and
+// loads machine images.
+package image
+
+//go:generate go run generate.go
+
+import (
+	"bufio"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/weaveworks/footloose/pkg/docker"
+)
+
+// Repository is where the footloose images are published.
+const Repository = "quay.io/footloose"
+
+// Distros returns the distros footloose has images for, sorted.
+func Distros() []string {
+	var distros []string
+	for distro := range dockerfiles {
+		distros = append(distros, distro)
+	}
+	sort.Strings(distros)
+	return distros
+}
+
+// Name returns the name of the image of distro, tagged with tag.
+func Name(distro, tag string) string {
+	return Repository + "/" + distro + ":" + tag
+}
+
+// IsFootlooseImage returns whether name is one of the images footloose
+// provides, whatever its tag.
+func IsFootlooseImage(name string) bool {
+	if !strings.HasPrefix(name, Repository+"/") {
+		return false
+	}
+	distro := strings.TrimPrefix(name, Repository+"/")
+	if i := strings.IndexAny(distro, ":@"); i >= 0 {
+		distro = distro[:i]
+	}
+	_, ok := dockerfiles[distro]
+	return ok
+}
+
+// Dockerfile returns the Dockerfile of distro. When base isn't empty, the
+// image is built from base instead of the distro image, adding the systemd
+// and sshd layers footloose needs on top of it. base must be derived from the
+// distro image for its package manager and init system to match.
+func Dockerfile(distro, base string) (string, error) {
+	dockerfile, ok := dockerfiles[distro]
+	if !ok {
+		return "", errors.Errorf("unknown distro '%s', one of: %s", distro, strings.Join(Distros(), ", "))
+	}
+	if base == "" {
+		return dockerfile, nil
+	}
+
+	var out strings.Builder
+	replaced := false
+	scanner := bufio.NewScanner(strings.NewReader(dockerfile))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !replaced && strings.HasPrefix(strings.ToUpper(line), "FROM ") {
+			line = "FROM " + base
+			replaced = true
+		}
+		out.WriteString(line + "\n")
+	}
+	if !replaced {
+		return "", errors.Errorf("%s: Dockerfile without FROM instruction", distro)
+	}
+	return out.String(), nil
+}
+
+// Build builds the image of distro, from base if not empty, and tags it with
+// image.
+func Build(distro, base, image string) error {
+	dockerfile, err := Dockerfile(distro, base)
+	if err != nil {
+		return err
+	}
+	return errors.Wrapf(docker.Build(image, strings.NewReader(dockerfile)), "building %s", image)
+}
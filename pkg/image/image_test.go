@@ -0,0 +1,39 @@
+package image
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDockerfilesGenerated checks the embedded Dockerfiles are up to date. Run
+// go generate in this directory after changing the images/ directory.
+func TestDockerfilesGenerated(t *testing.T) {
+	paths, err := filepath.Glob("../../images/*/Dockerfile")
+	assert.NoError(t, err)
+	assert.Equal(t, len(paths), len(dockerfiles))
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		assert.NoError(t, err)
+		distro := filepath.Base(filepath.Dir(path))
+		assert.Equal(t, string(data), dockerfiles[distro], distro)
+	}
+}
+
+func TestDockerfileBase(t *testing.T) {
+	dockerfile, err := Dockerfile("ubuntu18.04", "")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(dockerfile, "FROM ubuntu:18.04\n"))
+
+	dockerfile, err = Dockerfile("ubuntu18.04", "example.com/ubuntu:custom")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(dockerfile, "FROM example.com/ubuntu:custom\n"))
+	assert.Equal(t, 1, strings.Count(dockerfile, "FROM "))
+	assert.Contains(t, dockerfile, "openssh-server")
+
+	_, err = Dockerfile("plan9", "")
+	assert.Error(t, err)
+}
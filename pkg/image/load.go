@@ -0,0 +1,37 @@
+package image
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+
+	"github.com/weaveworks/footloose/pkg/docker"
+)
+
+// ArchiveTags returns the sorted "repo:tag" images of archive, a tarball
+// written by `docker save`. Archives without tagged images are rejected:
+// machines couldn't refer to their images.
+func ArchiveTags(archive string) ([]string, error) {
+	tags, err := docker.GetArchiveTags(archive)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s is not a docker image archive", archive)
+	}
+	if len(tags) == 0 {
+		return nil, errors.Errorf("%s doesn't contain any tagged image", archive)
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// Load loads the images of archive in the docker daemon, so machines can be
+// created without pulling them. It returns the loaded images.
+func Load(archive string) ([]string, error) {
+	tags, err := ArchiveTags(archive)
+	if err != nil {
+		return nil, err
+	}
+	if err := docker.Load(archive); err != nil {
+		return nil, errors.Wrapf(err, "load %s", archive)
+	}
+	return tags, nil
+}
@@ -0,0 +1,47 @@
+package image
+
+import (
+	"archive/tar"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeArchive(t *testing.T, path, repositories string) {
+	f, err := os.Create(path)
+	assert.NoError(t, err)
+	defer f.Close()
+	tw := tar.NewWriter(f)
+	assert.NoError(t, tw.WriteHeader(&tar.Header{Name: "repositories", Mode: 0644, Size: int64(len(repositories))}))
+	_, err = tw.Write([]byte(repositories))
+	assert.NoError(t, err)
+	assert.NoError(t, tw.Close())
+}
+
+func TestArchiveTags(t *testing.T) {
+	dir, err := ioutil.TempDir("", "footloose-load")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, "images.tar")
+	writeArchive(t, archive, `{"alpine":{"3.10":"abc"},"quay.io/footloose/centos7":{"0.6.3":"def"}}`)
+	tags, err := ArchiveTags(archive)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alpine:3.10", "quay.io/footloose/centos7:0.6.3"}, tags)
+
+	untagged := filepath.Join(dir, "untagged.tar")
+	writeArchive(t, untagged, `{}`)
+	_, err = ArchiveTags(untagged)
+	assert.Error(t, err)
+
+	notArchive := filepath.Join(dir, "footloose.yaml")
+	assert.NoError(t, ioutil.WriteFile(notArchive, []byte("cluster: {}\n"), 0644))
+	_, err = ArchiveTags(notArchive)
+	assert.Error(t, err)
+
+	_, err = Load(notArchive)
+	assert.Error(t, err)
+}
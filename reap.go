@@ -0,0 +1,38 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/weaveworks/footloose/pkg/cluster"
+)
+
+var reapCmd = &cobra.Command{
+	Use:   "reap",
+	Short: "Delete the clusters which TTL has expired",
+	Long: `Delete the clusters which TTL has expired.
+
+A cluster expires once its TTL (cluster.ttl) has elapsed since its oldest
+machine was created, or at the deadline set by 'footloose extend'. Clusters
+without a TTL are never reaped.`,
+	Args: cobra.NoArgs,
+	RunE: reap,
+}
+
+var reapOptions struct {
+	parallel int
+}
+
+func init() {
+	reapCmd.Flags().IntVarP(&reapOptions.parallel, "parallel", "p", 1, "Number of machines to operate on concurrently")
+	footloose.AddCommand(reapCmd)
+}
+
+func reap(cmd *cobra.Command, args []string) error {
+	deleted, err := cluster.Reap(reapOptions.parallel)
+	for _, name := range deleted {
+		fmt.Println(name)
+	}
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -23,6 +24,7 @@ var serveOptions struct {
 	listen       string
 	keyStorePath string
 	debug        bool
+	reapInterval time.Duration
 }
 
 func baseURI(addr string) (string, error) {
@@ -40,9 +42,19 @@ func init() {
 	serveCmd.Flags().StringVarP(&serveOptions.listen, "listen", "l", ":2444", "Cluster configuration file")
 	serveCmd.Flags().StringVar(&serveOptions.keyStorePath, "keystore-path", defaultKeyStorePath, "Path of the public keys store")
 	serveCmd.Flags().BoolVar(&serveOptions.debug, "debug", false, "Enable debug")
+	serveCmd.Flags().DurationVar(&serveOptions.reapInterval, "reap-interval", 0, "Delete clusters which TTL has expired at this interval (disabled when 0)")
 	footloose.AddCommand(serveCmd)
 }
 
+// reapPeriodically deletes the clusters which TTL has expired every interval.
+func reapPeriodically(interval time.Duration) {
+	for range time.Tick(interval) {
+		if _, err := cluster.Reap(1); err != nil {
+			log.Errorf("Reaping expired clusters: %v", err)
+		}
+	}
+}
+
 func serve(cmd *cobra.Command, args []string) error {
 	opts := &serveOptions
 
@@ -60,6 +72,11 @@ func serve(cmd *cobra.Command, args []string) error {
 
 	log.Infof("Key store successfully initialized in path: %s\n", opts.keyStorePath)
 
+	if opts.reapInterval > 0 {
+		log.Infof("Reaping expired clusters every %v\n", opts.reapInterval)
+		go reapPeriodically(opts.reapInterval)
+	}
+
 	api := api.New(baseURI, keyStore, opts.debug)
 	router := api.Router()
 
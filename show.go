@@ -21,17 +21,19 @@ Optionally, provide show with a hostname to look for a specific machine. Exp: 's
 var showOptions struct {
 	output string
 	config string
+	name   string
 }
 
 func init() {
 	showCmd.Flags().StringVarP(&showOptions.config, "config", "c", Footloose, "Cluster configuration file")
 	showCmd.Flags().StringVarP(&showOptions.output, "output", "o", "table", "Output formatting options: {json,table}.")
+	showCmd.Flags().StringVarP(&showOptions.name, "name", "n", "", "Use the recorded state of the cluster with this name instead of the configuration file")
 	footloose.AddCommand(showCmd)
 }
 
 // show will show all machines in a given cluster.
 func show(cmd *cobra.Command, args []string) error {
-	c, err := cluster.NewFromFile(configFile(showOptions.config))
+	c, err := newCluster(showOptions.config, showOptions.name)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/weaveworks/footloose/pkg/cluster"
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot [NAME]",
+	Short: "Commit the cluster machines to images",
+	Long: `Commit the filesystem of each cluster machine to an image and record them in
+a snapshot. The cluster can then be recreated from the snapshot with
+'footloose create --from-snapshot NAME'.
+
+The snapshot is named after the current time when no name is given.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: snapshot,
+}
+
+var snapshotOptions struct {
+	config   string
+	parallel int
+}
+
+func init() {
+	snapshotCmd.Flags().StringVarP(&snapshotOptions.config, "config", "c", Footloose, "Cluster configuration file")
+	snapshotCmd.Flags().IntVarP(&snapshotOptions.parallel, "parallel", "p", 1, "Number of machines to operate on concurrently")
+	footloose.AddCommand(snapshotCmd)
+}
+
+func snapshot(cmd *cobra.Command, args []string) error {
+	cluster, err := cluster.NewFromFile(configFile(snapshotOptions.config))
+	if err != nil {
+		return err
+	}
+	cluster.SetParallel(snapshotOptions.parallel)
+
+	name := time.Now().Format("20060102-150405")
+	if len(args) > 0 {
+		name = args[0]
+	}
+	s, err := cluster.Snapshot(name)
+	if err != nil {
+		return err
+	}
+	fmt.Println(s.Name)
+	return nil
+}
@@ -7,8 +7,6 @@ import (
 	"strings"
 
 	"github.com/spf13/cobra"
-
-	"github.com/weaveworks/footloose/pkg/cluster"
 )
 
 var sshCmd = &cobra.Command{
@@ -20,15 +18,17 @@ var sshCmd = &cobra.Command{
 
 var sshOptions struct {
 	config string
+	name   string
 }
 
 func init() {
 	sshCmd.Flags().StringVarP(&sshOptions.config, "config", "c", Footloose, "Cluster configuration file")
+	sshCmd.Flags().StringVarP(&sshOptions.name, "name", "n", "", "Use the recorded state of the cluster with this name instead of the configuration file")
 	footloose.AddCommand(sshCmd)
 }
 
 func ssh(cmd *cobra.Command, args []string) error {
-	cluster, err := cluster.NewFromFile(configFile(sshOptions.config))
+	cluster, err := newCluster(sshOptions.config, sshOptions.name)
 	if err != nil {
 		return err
 	}
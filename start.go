@@ -13,11 +13,13 @@ var startCmd = &cobra.Command{
 }
 
 var startOptions struct {
-	config string
+	config   string
+	parallel int
 }
 
 func init() {
 	startCmd.Flags().StringVarP(&startOptions.config, "config", "c", Footloose, "Cluster configuration file")
+	startCmd.Flags().IntVarP(&startOptions.parallel, "parallel", "p", 1, "Number of machines to operate on concurrently")
 	footloose.AddCommand(startCmd)
 }
 
@@ -26,5 +28,6 @@ func start(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	cluster.SetParallel(startOptions.parallel)
 	return cluster.Start(args)
 }
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/weaveworks/footloose/pkg/cluster"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats [HOSTNAME...]",
+	Short: "Show the resource usage of cluster machines",
+	Long: `Show the CPU, memory, network I/O and block I/O usage of the running cluster
+machines, or of the given machines.
+
+With --stream, stats are shown again every --interval until Ctrl-C.`,
+	RunE: stats,
+}
+
+var statsOptions struct {
+	config   string
+	name     string
+	output   string
+	stream   bool
+	interval time.Duration
+}
+
+func init() {
+	statsCmd.Flags().StringVarP(&statsOptions.config, "config", "c", Footloose, "Cluster configuration file")
+	statsCmd.Flags().StringVarP(&statsOptions.name, "name", "n", "", "Use the recorded state of the cluster with this name instead of the configuration file")
+	statsCmd.Flags().StringVarP(&statsOptions.output, "output", "o", "table", "Output formatting options: {json,table}.")
+	statsCmd.Flags().BoolVar(&statsOptions.stream, "stream", false, "Keep showing stats until interrupted")
+	statsCmd.Flags().DurationVar(&statsOptions.interval, "interval", 5*time.Second, "Time between stats when streaming")
+	footloose.AddCommand(statsCmd)
+}
+
+func stats(cmd *cobra.Command, args []string) error {
+	var formatter cluster.StatsFormatter
+	switch statsOptions.output {
+	case "json":
+		formatter = new(cluster.JSONStatsFormatter)
+	case "table":
+		formatter = new(cluster.TableStatsFormatter)
+	default:
+		return fmt.Errorf("unknown formatter '%s'", statsOptions.output)
+	}
+	c, err := newCluster(statsOptions.config, statsOptions.name)
+	if err != nil {
+		return err
+	}
+
+	if !statsOptions.stream {
+		s, err := c.Stats(args)
+		if err != nil {
+			return err
+		}
+		return formatter.FormatStats(os.Stdout, s)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		cancel()
+	}()
+
+	stream := make(chan []*cluster.MachineStats)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- c.StreamStats(ctx, args, statsOptions.interval, stream)
+	}()
+	for {
+		select {
+		case s := <-stream:
+			if err := formatter.FormatStats(os.Stdout, s); err != nil {
+				return err
+			}
+		case err := <-errc:
+			return err
+		}
+	}
+}
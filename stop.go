@@ -13,11 +13,13 @@ var stopCmd = &cobra.Command{
 }
 
 var stopOptions struct {
-	config string
+	config   string
+	parallel int
 }
 
 func init() {
 	stopCmd.Flags().StringVarP(&stopOptions.config, "config", "c", Footloose, "Cluster configuration file")
+	stopCmd.Flags().IntVarP(&stopOptions.parallel, "parallel", "p", 1, "Number of machines to operate on concurrently")
 	footloose.AddCommand(stopCmd)
 }
 
@@ -26,5 +28,6 @@ func stop(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
+	cluster.SetParallel(stopOptions.parallel)
 	return cluster.Stop(args)
 }
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/mitchellh/go-homedir"
+
+	"github.com/weaveworks/footloose/pkg/config"
+)
+
+// configTemplate is a cluster configuration `footloose config create` can
+// start from.
+type configTemplate struct {
+	name        string
+	description string
+	// path is the file user templates are read from, empty for the templates
+	// built in footloose.
+	path string
+	// config returns a fresh copy of built-in template configurations.
+	config func() config.Config
+}
+
+// defaultTemplateDir holds the user templates, on top of the directories given
+// with --template-dir.
+const defaultTemplateDir = "~/.footloose/templates"
+
+func footlooseImage(distro string) string {
+	return "quay.io/footloose/" + distro + ":" + imageTag(version)
+}
+
+func sshPort() []config.PortMapping {
+	return []config.PortMapping{{ContainerPort: 22}}
+}
+
+// builtinTemplates are the templates built in footloose.
+var builtinTemplates = []*configTemplate{{
+	name:        "single-node",
+	description: "A single CentOS 7 machine (the default configuration)",
+	config: func() config.Config {
+		return config.Config{
+			Cluster: config.Cluster{Name: "cluster", PrivateKey: "cluster-key"},
+			Machines: []config.MachineReplicas{{
+				Count: 1,
+				Spec: config.Machine{
+					Name:         "node%d",
+					Image:        footlooseImage("centos7"),
+					PortMappings: sshPort(),
+					Backend:      "docker",
+				},
+			}},
+		}
+	},
+}, {
+	name:        "multi-distro",
+	description: "One machine per distribution footloose provides images for",
+	config: func() config.Config {
+		conf := config.Config{
+			Cluster: config.Cluster{Name: "cluster", PrivateKey: "cluster-key"},
+		}
+		for _, distro := range []string{"amazonlinux2", "centos7", "debian10", "fedora29", "ubuntu18.04"} {
+			conf.Machines = append(conf.Machines, config.MachineReplicas{
+				Count: 1,
+				Spec: config.Machine{
+					Name:         strings.Replace(distro, ".", "", -1) + "-%d",
+					Image:        footlooseImage(distro),
+					PortMappings: sshPort(),
+					Backend:      "docker",
+				},
+			})
+		}
+		return conf
+	},
+}, {
+	name:        "docker-in-docker",
+	description: "A privileged machine able to run docker, see examples/docker-in-docker",
+	config: func() config.Config {
+		return config.Config{
+			Cluster: config.Cluster{Name: "cluster", PrivateKey: "cluster-key"},
+			Machines: []config.MachineReplicas{{
+				Count: 1,
+				Spec: config.Machine{
+					Name:         "node%d",
+					Image:        footlooseImage("centos7"),
+					PortMappings: sshPort(),
+					Privileged:   true,
+					Volumes: []config.Volume{{
+						Type:        "volume",
+						Destination: "/var/lib/docker",
+					}},
+					Backend: "docker",
+				},
+			}},
+		}
+	},
+}, {
+	name:        "user-defined-network",
+	description: "Three machines on a user-defined network, see examples/user-defined-network",
+	config: func() config.Config {
+		return config.Config{
+			Cluster: config.Cluster{Name: "cluster", PrivateKey: "cluster-key"},
+			Machines: []config.MachineReplicas{{
+				Count: 3,
+				Spec: config.Machine{
+					Name:         "node%d",
+					Image:        footlooseImage("centos7"),
+					Networks:     []string{"footloose-cluster"},
+					PortMappings: sshPort(),
+					Backend:      "docker",
+				},
+			}},
+		}
+	},
+}}
+
+// userTemplates returns the templates found in dirs, NAME.yaml files. Missing
+// directories are skipped.
+func userTemplates(dirs []string) ([]*configTemplate, error) {
+	var templates []*configTemplate
+	for _, dir := range dirs {
+		dir, err := homedir.Expand(dir)
+		if err != nil {
+			return nil, err
+		}
+		files, err := ioutil.ReadDir(dir)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if file.IsDir() || filepath.Ext(file.Name()) != ".yaml" {
+				continue
+			}
+			path := filepath.Join(dir, file.Name())
+			templates = append(templates, &configTemplate{
+				name:        strings.TrimSuffix(file.Name(), ".yaml"),
+				description: "User template " + path,
+				path:        path,
+			})
+		}
+	}
+	return templates, nil
+}
+
+// listTemplates returns the available templates, sorted by name. User
+// templates take precedence over the built-in templates with the same name,
+// and templates in the first directories over the ones in the last.
+func listTemplates(dirs []string) ([]*configTemplate, error) {
+	user, err := userTemplates(dirs)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]*configTemplate)
+	for _, t := range builtinTemplates {
+		byName[t.name] = t
+	}
+	seen := make(map[string]bool)
+	for _, t := range user {
+		if !seen[t.name] {
+			byName[t.name] = t
+			seen[t.name] = true
+		}
+	}
+	var templates []*configTemplate
+	for _, t := range byName {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].name < templates[j].name
+	})
+	return templates, nil
+}
+
+// templateDirs returns the directories to look for user templates in.
+func templateDirs(dirs []string) []string {
+	return append(append([]string{}, dirs...), defaultTemplateDir)
+}
+
+// loadTemplate returns the configuration of the template name.
+func loadTemplate(name string, dirs []string) (*config.Config, error) {
+	templates, err := listTemplates(dirs)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range templates {
+		if t.name != name {
+			continue
+		}
+		if t.path == "" {
+			conf := t.config()
+			return &conf, nil
+		}
+		data, err := ioutil.ReadFile(t.path)
+		if err != nil {
+			return nil, err
+		}
+		conf := &config.Config{}
+		if err := yaml.Unmarshal(data, conf); err != nil {
+			return nil, fmt.Errorf("template %s: %v", name, err)
+		}
+		return conf, nil
+	}
+	return nil, fmt.Errorf("unknown template '%s', see 'footloose config templates'", name)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuiltinTemplates(t *testing.T) {
+	for _, template := range builtinTemplates {
+		conf, err := loadTemplate(template.name, nil)
+		assert.NoError(t, err, template.name)
+		assert.NoError(t, conf.Validate(), template.name)
+	}
+	_, err := loadTemplate("unknown", nil)
+	assert.Error(t, err)
+}
+
+func TestUserTemplates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "footloose-templates")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	write := func(name, data string) {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644))
+	}
+	write("single-node.yaml", `cluster:
+  name: mine
+machines:
+- count: 2
+  spec:
+    name: node%d
+    image: quay.io/footloose/ubuntu18.04
+    portMappings:
+    - containerPort: 22
+`)
+	write("README.md", "not a template")
+
+	templates, err := listTemplates([]string{dir, filepath.Join(dir, "missing")})
+	assert.NoError(t, err)
+	var names []string
+	for _, template := range templates {
+		names = append(names, template.name)
+	}
+	assert.Equal(t, []string{"docker-in-docker", "multi-distro", "single-node", "user-defined-network"}, names)
+
+	conf, err := loadTemplate("single-node", []string{dir})
+	assert.NoError(t, err)
+	assert.Equal(t, "mine", conf.Cluster.Name)
+	assert.Equal(t, 2, conf.Machines[0].Count)
+	assert.Equal(t, uint16(22), conf.Machines[0].Spec.PortMappings[0].ContainerPort)
+}
+
+func TestApplyConfigFlags(t *testing.T) {
+	conf, err := loadTemplate("multi-distro", nil)
+	assert.NoError(t, err)
+
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.AddFlagSet(configCreateCmd.PersistentFlags())
+	assert.NoError(t, flags.Parse([]string{"--name", "matrix", "--privileged"}))
+	defer func() {
+		defaultConfig.Cluster.Name = "cluster"
+		defaultConfig.Machines[0].Spec.Privileged = false
+	}()
+
+	applyConfigFlags(flags, conf)
+	assert.Equal(t, "matrix", conf.Cluster.Name)
+	assert.Equal(t, "cluster-key", conf.Cluster.PrivateKey)
+	for _, machines := range conf.Machines {
+		assert.True(t, machines.Spec.Privileged)
+		assert.Equal(t, 1, machines.Count)
+	}
+	// Flags not given don't override the template.
+	assert.Equal(t, "quay.io/footloose/amazonlinux2:latest", conf.Machines[0].Spec.Image)
+}
@@ -0,0 +1,6 @@
+# Test footloose cp can copy files into machines
+footloose config create --override --config %testName.footloose --name %testName --key %testName-key --image quay.io/footloose/%image
+footloose create --config %testName.footloose
+footloose --config %testName.footloose cp --mode 600 %testName.footloose node0:/tmp/footloose.yaml
+%out footloose --config %testName.footloose ssh root@node0 stat -c %a /tmp/footloose.yaml
+footloose delete --config %testName.footloose
@@ -0,0 +1,5 @@
+# Test footloose create --wait returns once machines can be used
+footloose config create --override --config %testName.footloose --name %testName --key %testName-key --image quay.io/footloose/%image
+footloose create --wait --config %testName.footloose
+%out footloose --config %testName.footloose ssh root@node0 whoami
+footloose delete --config %testName.footloose
@@ -0,0 +1,6 @@
+# Test machine hooks run after create and start
+footloose create --config %testName.yaml
+footloose stop --config %testName.yaml
+footloose start --config %testName.yaml
+%out footloose --config %testName.yaml ssh root@node0 cat /hooks
+footloose delete --config %testName.yaml
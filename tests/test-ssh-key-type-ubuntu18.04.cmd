@@ -0,0 +1,5 @@
+# Test footloose can generate and use ed25519 keys
+footloose config create --override --config %testName.footloose --name %testName --key %testName-key --key-type ed25519 --image quay.io/footloose/ubuntu18.04
+footloose create --config %testName.footloose
+%out footloose --config %testName.footloose ssh root@node0 whoami
+footloose delete --config %testName.footloose
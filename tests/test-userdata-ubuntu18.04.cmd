@@ -0,0 +1,5 @@
+# Test footloose applies cloud-config user data
+footloose create --config %testName.yaml
+%out footloose --config %testName.yaml ssh root@node0 cat /etc/app/app.conf
+%out footloose --config %testName.yaml ssh root@node0 test -s /etc/app/bob && echo ok
+footloose delete --config %testName.yaml
@@ -0,0 +1,5 @@
+# Test footloose can create non-root users with sudo
+footloose create --config %testName.yaml
+%out footloose --config %testName.yaml ssh alice@node0 id -u
+%out footloose --config %testName.yaml ssh alice@node0 sudo whoami
+footloose delete --config %testName.yaml
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/weaveworks/footloose/pkg/cluster"
+)
+
+var waitCmd = &cobra.Command{
+	Use:   "wait [MACHINE...]",
+	Short: "Wait for cluster machines to be ready",
+	Long: `Wait for cluster machines to pass their readiness checks.
+
+Machines are ready when all the readiness checks listed in their spec pass. By
+default, a machine is ready when its sshd answers connections.`,
+	RunE: wait,
+}
+
+var waitOptions struct {
+	config  string
+	timeout time.Duration
+}
+
+func init() {
+	waitCmd.Flags().StringVarP(&waitOptions.config, "config", "c", Footloose, "Cluster configuration file")
+	waitCmd.Flags().DurationVar(&waitOptions.timeout, "timeout", 5*time.Minute, "Maximum time to wait for machines to be ready")
+	footloose.AddCommand(waitCmd)
+}
+
+func wait(cmd *cobra.Command, args []string) error {
+	cluster, err := cluster.NewFromFile(configFile(waitOptions.config))
+	if err != nil {
+		return err
+	}
+	return cluster.Wait(args, waitOptions.timeout)
+}